@@ -1,29 +1,136 @@
 package api
 
 import (
+	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 const DateFormat = "2006-01-02"
 
+// Connection holds everything needed to talk to the TimeCamp API.
 type Connection struct {
 	ApiUrl string
 	Token  string
+	// Auth selects how Token is sent, defaults to AuthURL.
+	Auth AuthMode
+	// HTTPClient is used to send the requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// Retry controls retries of failed requests. If nil, requests are not retried.
+	Retry *RetryPolicy
+	// Limiter throttles all requests, including retries. If nil, requests are not throttled.
+	Limiter *RateLimiter
+	// Location is the time zone of the TimeCamp account, used for the start time of timers. If nil, UTC is used.
+	// Pass it to time entry accessors like TimeEntry.StartIn to use the same zone for entries.
+	Location *time.Location
 }
 
-func httpGet(url string) ([]byte, error) {
+func (c Connection) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func httpGet(ctx context.Context, c Connection, endpoint string) ([]byte, error) {
+	return do(ctx, c, http.MethodGet, endpoint, nil)
+}
+
+// do sends a request to the api, retrying it according to the connection's retry policy.
+// A non-nil form is sent url-encoded in the request body.
+func do(ctx context.Context, c Connection, method, endpoint string, form url.Values) ([]byte, error) {
 	var data []byte
+	err := withRetry(ctx, c, method, endpoint, func() error {
+		var err error
+		data, err = send(ctx, c, method, endpoint, form)
+		return err
+	})
+	return data, err
+}
 
-	response, err := http.Get(url)
-	if err != nil {
-		return nil, err
+// open is like do, but returns the response body for reading it incrementally. The caller must close it.
+// Errors while reading the body are not retried.
+func open(ctx context.Context, c Connection, method, endpoint string, form url.Values) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := withRetry(ctx, c, method, endpoint, func() error {
+		var err error
+		body, err = openOnce(ctx, c, method, endpoint, form)
+		return err
+	})
+	return body, err
+}
+
+// withRetry calls attempt until it succeeds or the connection's retry policy gives up.
+func withRetry(ctx context.Context, c Connection, method, endpoint string, attempt func() error) error {
+	attempts := c.Retry.attempts(method)
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil || n >= attempts || !retryable(err) {
+			return err
+		}
+
+		wait := c.Retry.backoff(n, err)
+		if c.Retry.OnRetry != nil {
+			c.Retry.OnRetry(RetryEvent{Attempt: n, Method: method, Endpoint: c.Redact(endpoint), Err: err, Wait: wait})
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
 	}
+}
 
-	data, err = ioutil.ReadAll(response.Body)
+// send executes a single attempt of a request and reads the whole response.
+func send(ctx context.Context, c Connection, method, endpoint string, form url.Values) ([]byte, error) {
+	body, err := openOnce(ctx, c, method, endpoint, form)
 	if err != nil {
 		return nil, err
 	}
+	defer body.Close()
 
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, redactError(c, err)
+	}
 	return data, nil
 }
+
+// openOnce executes a single attempt of a request and returns the body of a successful response.
+func openOnce(ctx context.Context, c Connection, method, endpoint string, form url.Values) (io.ReadCloser, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx, c.Token); err != nil {
+			return nil, err
+		}
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, redactError(c, err)
+	}
+	if form != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	authorize(c, request)
+
+	response, err := c.httpClient().Do(request)
+	if err != nil {
+		return nil, redactError(c, err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		defer response.Body.Close()
+		// Read just enough of the (often HTML) error page for a helpful message, plus a token crossing the limit.
+		body, _ := ioutil.ReadAll(io.LimitReader(response.Body, int64(maxErrorBody+len(c.Token)+1)))
+		return nil, newError(response, c.Redact(endpoint), c.redactErrorBody(body))
+	}
+
+	return response.Body, nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type countingTransport struct {
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.calls++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestConnection_HTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"1":{"task_id":1,"name":"Task A"}}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN", HTTPClient: &http.Client{Transport: transport}}
+
+	tasks, err := GetTasks(c, TaskParams{})
+	if err != nil {
+		t.Fatalf("GetTasks() error = %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Name != "Task A" {
+		t.Errorf("GetTasks() got = %v", tasks)
+	}
+	if transport.calls != 1 {
+		t.Errorf("custom client was called %d times, want 1", transport.calls)
+	}
+}
+
+func TestGetTimeEntriesContext_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+	_, err := GetTimeEntriesContext(ctx, c, TimeEntryParams{From: time.Time{}, To: time.Time{}.Add(time.Hour)})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetTimeEntriesContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
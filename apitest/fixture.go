@@ -0,0 +1,104 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/rupkoe/timecamp-api"
+)
+
+// LoadTasks reads tasks from a cassette or from a JSON dump of the /tasks response.
+// The dump may also be a JSON array of tasks. Tasks are returned in api.OrderByID tree order, like api.GetTasks does.
+func LoadTasks(path string) ([]api.Task, error) {
+	bodies, err := loadBodies(path, "tasks")
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[api.ID]api.Task)
+	for _, body := range bodies {
+		tasks, err := decodeTasks(body)
+		if err != nil {
+			return nil, fmt.Errorf("apitest: invalid tasks in %s: %v", path, err)
+		}
+		for _, task := range tasks {
+			byID[task.TaskID] = task
+		}
+	}
+	var tasks []api.Task
+	for _, task := range byID {
+		tasks = append(tasks, task)
+	}
+	api.SortTasks(tasks, api.OrderByID)
+	return tasks, nil
+}
+
+// LoadTimeEntries reads time entries from a cassette or from a JSON dump of the /entries response.
+// Entries with the same non-zero ID recorded in several responses, e.g. by windowed requests, are returned once in recorded order.
+func LoadTimeEntries(path string) ([]api.TimeEntry, error) {
+	bodies, err := loadBodies(path, "entries")
+	if err != nil {
+		return nil, err
+	}
+	var entries []api.TimeEntry
+	seen := make(map[api.ID]bool)
+	for _, body := range bodies {
+		var page []api.TimeEntry
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("apitest: invalid time entries in %s: %v", path, err)
+		}
+		for _, entry := range page {
+			// Entries without ID cannot be told apart, keep all of them.
+			if entry.ID != 0 {
+				if seen[entry.ID] {
+					continue
+				}
+				seen[entry.ID] = true
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func decodeTasks(data []byte) ([]api.Task, error) {
+	var keyed map[string]api.Task
+	if err := json.Unmarshal(data, &keyed); err == nil {
+		var tasks []api.Task
+		for _, task := range keyed {
+			tasks = append(tasks, task)
+		}
+		return tasks, nil
+	}
+	var tasks []api.Task
+	err := json.Unmarshal(data, &tasks)
+	return tasks, err
+}
+
+// loadBodies returns the bodies of all successful GET requests of resource in a cassette,
+// or the whole file if it is no cassette.
+func loadBodies(path, resource string) ([][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette struct {
+		Interactions *[]Interaction `json:"interactions"`
+	}
+	if err := json.Unmarshal(data, &cassette); err != nil || cassette.Interactions == nil {
+		return [][]byte{data}, nil
+	}
+
+	var bodies [][]byte
+	for _, interaction := range *cassette.Interactions {
+		if interaction.Method == http.MethodGet && interaction.resource() == resource &&
+			interaction.Status >= 200 && interaction.Status <= 299 {
+			bodies = append(bodies, []byte(interaction.Body))
+		}
+	}
+	if len(bodies) == 0 {
+		return nil, fmt.Errorf("apitest: no %s recorded in %s", resource, path)
+	}
+	return bodies, nil
+}
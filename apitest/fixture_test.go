@@ -0,0 +1,76 @@
+package apitest_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rupkoe/timecamp-api"
+	"github.com/rupkoe/timecamp-api/apitest"
+)
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadTasks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []api.ID
+	}{
+		{"keyed dump", `{"12":{"task_id":"12","parent_id":"1"},"1":{"task_id":"1","parent_id":"0"}}`, []api.ID{1, 12}},
+		{"array dump", `[{"task_id":2},{"task_id":1}]`, []api.ID{1, 2}},
+		{"cassette", `{"interactions":[
+			{"method":"GET","url":"https://x/tasks/format/json/api_token/REDACTED","status":200,"body":"{\"1\":{\"task_id\":\"1\"}}"},
+			{"method":"GET","url":"https://x/tasks/format/json/api_token/REDACTED","status":429,"body":"slow down"},
+			{"method":"POST","url":"https://x/tasks/format/json/api_token/REDACTED","status":200,"body":"{\"3\":{\"task_id\":\"3\"}}"},
+			{"method":"GET","url":"https://x/tasks/format/json/api_token/REDACTED/exclude_archived/1","status":200,"body":"{\"2\":{\"task_id\":\"2\"}}"}
+		]}`, []api.ID{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := apitest.LoadTasks(writeFile(t, tt.content))
+			if err != nil {
+				t.Fatalf("LoadTasks() error = %v", err)
+			}
+			if got := taskIDs(tasks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadTasks() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTimeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []api.ID
+		wantErr bool
+	}{
+		{"dump", `[{"id":1,"duration":"60","date":"2021-01-04"},{"id":2,"duration":"60","date":"2021-01-05"}]`, []api.ID{1, 2}, false},
+		{"cassette", `{"interactions":[
+			{"method":"GET","url":"https://x/entries/format/json/from/2021-01-01/to/2021-01-07","status":200,"body":"[{\"id\":1,\"date\":\"2021-01-04\"}]"},
+			{"method":"GET","url":"https://x/entries/format/json/from/2021-01-04/to/2021-01-10","status":200,"body":"[{\"id\":1,\"date\":\"2021-01-04\"},{\"id\":3,\"date\":\"2021-01-08\"}]"}
+		]}`, []api.ID{1, 3}, false},
+		{"without IDs", `[{"date":"2021-01-04"},{"date":"2021-01-05"}]`, []api.ID{0, 0}, false},
+		{"no entries", `{"interactions":[]}`, nil, true},
+		{"invalid", `{"id":1}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := apitest.LoadTimeEntries(writeFile(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTimeEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := entryIDs(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadTimeEntries() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
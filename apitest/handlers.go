@@ -0,0 +1,606 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rupkoe/timecamp-api"
+)
+
+func parseBody(r *http.Request) (map[string]string, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	form := make(map[string]string)
+	for key, v := range values {
+		form[key] = v[0]
+	}
+	return form, nil
+}
+
+func parseID(s string) (api.ID, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return api.ID(n), nil
+}
+
+func parseIDs(s string) ([]api.ID, error) {
+	var ids []api.ID
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+		id, err := parseID(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// taskJSON encodes a task the way TimeCamp does, with IDs as strings.
+func taskJSON(task api.Task) (map[string]interface{}, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"task_id", "parent_id", "assigned_by", "root_group_id"} {
+		fields[key] = fmt.Sprint(fields[key])
+	}
+	users := make(map[string]interface{})
+	for id, assignment := range task.Users {
+		users[id.String()] = map[string]string{
+			"user_id": id.String(),
+			"role_id": strconv.Itoa(int(assignment.Role)),
+		}
+	}
+	if len(users) == 0 {
+		fields["users"] = []interface{}{}
+	} else {
+		fields["users"] = users
+	}
+	return fields, nil
+}
+
+func (s *Server) handleTasks(method string, params map[string]string) (interface{}, int, error) {
+	switch method {
+	case http.MethodGet:
+		// Mirrors api.TaskParams: exclude_archived=0 returns active tasks, 1 archived ones.
+		result := make(map[string]interface{})
+		for id, task := range s.tasks {
+			if (params["exclude_archived"] == "0" && task.Archived != 0) ||
+				(params["exclude_archived"] == "1" && task.Archived == 0) {
+				continue
+			}
+			fields, err := taskJSON(task)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			result[id.String()] = fields
+		}
+		return result, http.StatusOK, nil
+
+	case http.MethodPost:
+		if params["name"] == "" {
+			return badRequest("name is required")
+		}
+		task, err := s.applyTaskParams(api.Task{}, params)
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		task = s.addTask(task)
+		fields, err := taskJSON(task)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return map[string]interface{}{task.TaskID.String(): fields}, http.StatusOK, nil
+
+	case http.MethodPut:
+		id, err := parseID(params["task_id"])
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		task, ok := s.tasks[id]
+		if !ok {
+			return notFound()
+		}
+		task, err = s.applyTaskParams(task, params)
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		s.tasks[id] = task
+		s.relevel(id, make(map[api.ID]bool))
+		return map[string]string{"task_id": id.String()}, http.StatusOK, nil
+	}
+	return methodNotAllowed()
+}
+
+// applyTaskParams sets the fields of task given in params.
+func (s *Server) applyTaskParams(task api.Task, params map[string]string) (api.Task, error) {
+	ints := map[string]*int{"billable": &task.Billable, "budgeted": &task.Budgeted, "archived": &task.Archived}
+	for key, field := range ints {
+		if value, ok := params[key]; ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return task, fmt.Errorf("invalid %s %q", key, value)
+			}
+			*field = n
+		}
+	}
+	strs := map[string]*string{"name": &task.Name, "budget_unit": &task.BudgetUnit, "tags": &task.Tags,
+		"note": &task.Note, "color": &task.Color}
+	for key, field := range strs {
+		if value, ok := params[key]; ok {
+			*field = value
+		}
+	}
+	if value, ok := params["parent_id"]; ok {
+		parentID, err := parseID(value)
+		if err != nil {
+			return task, err
+		}
+		if _, exists := s.tasks[parentID]; parentID != 0 && !exists {
+			return task, fmt.Errorf("parent task %d not found", parentID)
+		}
+		// Walk up from the new parent, a task cannot become a subtask of itself or of its subtasks.
+		seen := make(map[api.ID]bool)
+		for id := parentID; task.TaskID != 0 && id != 0 && !seen[id]; id = s.tasks[id].ParentID {
+			if id == task.TaskID {
+				return task, fmt.Errorf("task %d cannot be moved below itself", task.TaskID)
+			}
+			seen[id] = true
+		}
+		task.ParentID = parentID
+		task.Level = 0
+	}
+	return task, nil
+}
+
+// relevel recalculates the level of a task and its subtasks. seen guards against cycles of tasks added with AddTask.
+func (s *Server) relevel(id api.ID, seen map[api.ID]bool) {
+	seen[id] = true
+	task := s.tasks[id]
+	task.Level = 1
+	if parent, ok := s.tasks[task.ParentID]; ok {
+		task.Level = parent.Level + 1
+	}
+	s.tasks[id] = task
+	for childID, child := range s.tasks {
+		if child.ParentID == id && !seen[childID] {
+			s.relevel(childID, seen)
+		}
+	}
+}
+
+func (s *Server) handleEntries(method string, params map[string]string) (interface{}, int, error) {
+	switch method {
+	case http.MethodGet:
+		return s.getEntries(params)
+
+	case http.MethodPost:
+		entry, err := s.applyEntryParams(api.TimeEntry{}, params)
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		if entry.Date.IsZero() {
+			return badRequest("date is required")
+		}
+		entry.ID = s.newID()
+		s.entries[entry.ID] = entry
+		// TimeCamp sends the new ID as string.
+		return map[string]string{"entry_id": entry.ID.String()}, http.StatusOK, nil
+
+	case http.MethodPut:
+		id, err := parseID(params["id"])
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		entry, ok := s.entries[id]
+		if !ok {
+			return notFound()
+		}
+		if entry, err = s.applyEntryParams(entry, params); err != nil {
+			return badRequest("%v", err)
+		}
+		s.entries[id] = entry
+		return map[string]string{"entry_id": id.String()}, http.StatusOK, nil
+
+	case http.MethodDelete:
+		id, err := parseID(params["id"])
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		if _, ok := s.entries[id]; !ok {
+			return notFound()
+		}
+		delete(s.entries, id)
+		delete(s.entryTags, id)
+		return map[string]string{"entry_id": id.String()}, http.StatusOK, nil
+	}
+	return methodNotAllowed()
+}
+
+func (s *Server) getEntries(params map[string]string) (interface{}, int, error) {
+	from, err := time.Parse(api.DateFormat, params["from"])
+	if err != nil {
+		return badRequest("invalid from date %q", params["from"])
+	}
+	to, err := time.Parse(api.DateFormat, params["to"])
+	if err != nil {
+		return badRequest("invalid to date %q", params["to"])
+	}
+	taskIDs, err := parseIDs(params["task_ids"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	userIDs, err := parseIDs(params["user_ids"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+
+	tasks := make(map[api.ID]bool)
+	for _, id := range taskIDs {
+		tasks[id] = true
+		if params["with_subtasks"] == "1" {
+			s.addSubtasks(id, tasks)
+		}
+	}
+	users := make(map[api.ID]bool)
+	for _, id := range userIDs {
+		users[id] = true
+	}
+
+	entries := []api.TimeEntry{}
+	for _, entry := range s.entries {
+		if entry.Date.Before(from) || entry.Date.After(to) ||
+			(len(tasks) > 0 && !tasks[entry.TaskID]) || (len(users) > 0 && !users[entry.UserID]) {
+			continue
+		}
+		if params["opt_fields"] == "tags" {
+			entry.Tags = s.tagsOf(entry.ID)
+			if entry.Tags == nil {
+				entry.Tags = []api.Tag{}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, http.StatusOK, nil
+}
+
+func (s *Server) addSubtasks(parentID api.ID, tasks map[api.ID]bool) {
+	for id, task := range s.tasks {
+		if task.ParentID == parentID && !tasks[id] {
+			tasks[id] = true
+			s.addSubtasks(id, tasks)
+		}
+	}
+}
+
+// applyEntryParams sets the fields of entry given in params.
+func (s *Server) applyEntryParams(entry api.TimeEntry, params map[string]string) (api.TimeEntry, error) {
+	var err error
+	if value, ok := params["date"]; ok {
+		if entry.Date, err = time.Parse(api.DateFormat, value); err != nil {
+			return entry, fmt.Errorf("invalid date %q", value)
+		}
+	}
+	if value, ok := params["start_time"]; ok {
+		if entry.StartTime, err = time.Parse(api.TimeFormat, value); err != nil {
+			return entry, fmt.Errorf("invalid start_time %q", value)
+		}
+	}
+	if value, ok := params["end_time"]; ok {
+		if entry.EndTime, err = time.Parse(api.TimeFormat, value); err != nil {
+			return entry, fmt.Errorf("invalid end_time %q", value)
+		}
+	}
+	if value, ok := params["duration"]; ok {
+		secs, err := strconv.Atoi(value)
+		if err != nil || secs < 0 {
+			return entry, fmt.Errorf("invalid duration %q", value)
+		}
+		entry.Duration = time.Duration(secs) * time.Second
+	} else if !entry.StartTime.IsZero() && !entry.EndTime.IsZero() {
+		entry.Duration = entry.EndTime.Sub(entry.StartTime)
+		if entry.Duration < 0 {
+			entry.Duration += 24 * time.Hour
+		}
+	}
+	if value, ok := params["task_id"]; ok {
+		if entry.TaskID, err = parseID(value); err != nil {
+			return entry, err
+		}
+		if task, exists := s.tasks[entry.TaskID]; exists {
+			entry.Name = task.Name
+		}
+	}
+	if value, ok := params["note"]; ok {
+		entry.Description = value
+	}
+	if value, ok := params["billable"]; ok {
+		entry.Billable = 0
+		if value == "1" {
+			entry.Billable = 1
+		}
+	}
+	return entry, nil
+}
+
+func (s *Server) handleTimer(method string, params map[string]string) (interface{}, int, error) {
+	if method != http.MethodPost {
+		return methodNotAllowed()
+	}
+	switch params["action"] {
+	case "start":
+		if s.timer != nil {
+			s.stopTimer()
+		}
+		taskID, err := parseID(params["task_id"])
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		start := now().UTC().Truncate(time.Second)
+		s.timer = &api.Timer{TimerID: s.newID(), EntryID: s.newID(), TaskID: taskID, StartedAt: start}
+		s.timerNote = params["note"]
+		return map[string]string{
+			"new_timer_id": s.timer.TimerID.String(),
+			"entry_id":     s.timer.EntryID.String(),
+		}, http.StatusOK, nil
+
+	case "stop":
+		if s.timer == nil {
+			return badRequest("no timer running")
+		}
+		timer := s.stopTimer()
+		return map[string]interface{}{
+			"elapsed":  int(timer.Elapsed / time.Second),
+			"entry_id": timer.EntryID.String(),
+			"timer_id": timer.TimerID.String(),
+		}, http.StatusOK, nil
+
+	case "status":
+		if s.timer == nil {
+			return map[string]interface{}{"isTimerRunning": false, "elapsed": 0}, http.StatusOK, nil
+		}
+		return map[string]interface{}{
+			"isTimerRunning": true,
+			"elapsed":        strconv.Itoa(int(now().UTC().Sub(s.timer.StartedAt) / time.Second)),
+			"timer_id":       s.timer.TimerID.String(),
+			"entry_id":       s.timer.EntryID.String(),
+			"task_id":        s.timer.TaskID.String(),
+			"start_time":     s.timer.StartedAt.Format(api.DateTimeFormat),
+		}, http.StatusOK, nil
+	}
+	return badRequest("unknown action %q", params["action"])
+}
+
+// stopTimer stops the running timer and books its time entry.
+func (s *Server) stopTimer() api.Timer {
+	timer := *s.timer
+	timer.Elapsed = now().UTC().Truncate(time.Second).Sub(timer.StartedAt)
+	s.timer = nil
+
+	date := time.Date(timer.StartedAt.Year(), timer.StartedAt.Month(), timer.StartedAt.Day(), 0, 0, 0, 0, time.UTC)
+	entry := api.TimeEntry{
+		ID:          timer.EntryID,
+		TaskID:      timer.TaskID,
+		Description: s.timerNote,
+		Date:        date,
+		Duration:    timer.Elapsed,
+		StartTime:   time.Date(0, 1, 1, timer.StartedAt.Hour(), timer.StartedAt.Minute(), timer.StartedAt.Second(), 0, time.UTC),
+	}
+	end := timer.StartedAt.Add(timer.Elapsed)
+	entry.EndTime = time.Date(0, 1, 1, end.Hour(), end.Minute(), end.Second(), 0, time.UTC)
+	if task, ok := s.tasks[timer.TaskID]; ok {
+		entry.Name = task.Name
+	}
+	s.entries[entry.ID] = entry
+	return timer
+}
+
+// userJSON encodes a user the way TimeCamp does, with numbers as strings.
+func userJSON(user api.User) map[string]string {
+	fields := map[string]string{
+		"user_id":      user.UserID.String(),
+		"group_id":     user.GroupID.String(),
+		"email":        user.Email,
+		"display_name": user.DisplayName,
+		"login_count":  strconv.Itoa(user.LoginCount),
+		"login_time":   user.LoginTime,
+	}
+	if user.Role != 0 {
+		fields["role_id"] = strconv.Itoa(int(user.Role))
+	}
+	return fields
+}
+
+func (s *Server) handleUsers(method string, params map[string]string) (interface{}, int, error) {
+	if method != http.MethodGet {
+		return methodNotAllowed()
+	}
+	users := []map[string]string{}
+	for _, user := range s.users {
+		users = append(users, userJSON(user))
+	}
+	return users, http.StatusOK, nil
+}
+
+func (s *Server) handleUser(method string, params map[string]string) (interface{}, int, error) {
+	if method != http.MethodGet {
+		return methodNotAllowed()
+	}
+	id, err := parseID(params["user_id"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	for _, user := range s.users {
+		if user.UserID == id {
+			return userJSON(user), http.StatusOK, nil
+		}
+	}
+	return notFound()
+}
+
+func (s *Server) handleGroups(method string, params map[string]string) (interface{}, int, error) {
+	if method != http.MethodGet {
+		return methodNotAllowed()
+	}
+	return append([]api.Group{}, s.groups...), http.StatusOK, nil
+}
+
+func (s *Server) handleTagLists(method string, params map[string]string) (interface{}, int, error) {
+	if method != http.MethodGet {
+		return methodNotAllowed()
+	}
+	listID, err := parseID(params["list_id"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	// Like most TimeCamp collections, lists and tags are objects keyed by ID.
+	result := make(map[string]interface{})
+	for _, list := range s.tagLists {
+		if listID != 0 && list.ID != listID {
+			continue
+		}
+		archived := "0"
+		if list.Archived {
+			archived = "1"
+		}
+		fields := map[string]interface{}{"id": list.ID.String(), "name": list.Name, "archived": archived}
+		if params["tags"] == "1" {
+			tags := make(map[string]interface{})
+			for _, tag := range list.Tags {
+				tags[tag.ID.String()] = map[string]string{"id": tag.ID.String(), "name": tag.Name}
+			}
+			fields["tags"] = tags
+		}
+		result[list.ID.String()] = fields
+	}
+	return result, http.StatusOK, nil
+}
+
+func (s *Server) handleEntryTags(method string, params map[string]string) (interface{}, int, error) {
+	if method == http.MethodGet {
+		entryIDs, err := parseIDs(params["entry_ids"])
+		if err != nil {
+			return badRequest("%v", err)
+		}
+		result := make(map[string]interface{})
+		for _, id := range entryIDs {
+			tags := []map[string]string{}
+			for _, tag := range s.tagsOf(id) {
+				tags = append(tags, map[string]string{
+					"tagListName": tag.ListName,
+					"tagListId":   tag.ListID.String(),
+					"tagId":       tag.ID.String(),
+					"name":        tag.Name,
+				})
+			}
+			result[id.String()] = tags
+		}
+		return result, http.StatusOK, nil
+	}
+
+	entryID, err := parseID(params["entry_id"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+	if _, ok := s.entries[entryID]; !ok {
+		return notFound()
+	}
+	tagIDs, err := parseIDs(params["tags"])
+	if err != nil {
+		return badRequest("%v", err)
+	}
+
+	switch method {
+	case http.MethodPost:
+		for _, id := range tagIDs {
+			tag, ok := s.tag(id)
+			if !ok {
+				return badRequest("tag %d not found", id)
+			}
+			if !hasTag(s.entryTags[entryID], id) {
+				s.entryTags[entryID] = append(s.entryTags[entryID], tag)
+			}
+		}
+		return map[string]string{"entry_id": entryID.String()}, http.StatusOK, nil
+
+	case http.MethodDelete:
+		var kept []api.Tag
+		for _, tag := range s.entryTags[entryID] {
+			if !containsID(tagIDs, tag.ID) {
+				kept = append(kept, tag)
+			}
+		}
+		s.entryTags[entryID] = kept
+		return map[string]string{"entry_id": entryID.String()}, http.StatusOK, nil
+	}
+	return methodNotAllowed()
+}
+
+// tagsOf returns a copy of the tags of a time entry.
+func (s *Server) tagsOf(entryID api.ID) []api.Tag {
+	tags := s.entryTags[entryID]
+	if len(tags) == 0 {
+		return nil
+	}
+	return append([]api.Tag(nil), tags...)
+}
+
+func (s *Server) tag(id api.ID) (api.Tag, bool) {
+	for _, list := range s.tagLists {
+		for _, tag := range list.Tags {
+			if tag.ID == id {
+				return tag, true
+			}
+		}
+	}
+	return api.Tag{}, false
+}
+
+func hasTag(tags []api.Tag, id api.ID) bool {
+	for _, tag := range tags {
+		if tag.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []api.ID, id api.ID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to the real API or replays a cassette.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette and never touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the API and records them into the cassette.
+	ModeRecord
+)
+
+// scrubbed replaces the API token in recorded requests and responses.
+const scrubbed = "REDACTED"
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response. The API token is scrubbed from all fields.
+type Interaction struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Form is the url-encoded request body, if any.
+	Form        string `json:"form,omitempty"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// resource returns the API resource of the interaction, e.g. "tasks".
+func (i Interaction) resource() string {
+	u, err := url.Parse(i.URL)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(u.Path, "/")
+	for n := 1; n < len(segments); n++ {
+		if segments[n] == "format" {
+			return segments[n-1]
+		}
+	}
+	return ""
+}
+
+// Recorder is an http.RoundTripper that records interactions with the TimeCamp API into a cassette file,
+// or replays them from it. Plug it into a connection with
+//
+//	connection.HTTPClient = recorder.Client()
+//
+// Replayed requests are matched by method, URL path and form, ignoring host and token.
+// Matching interactions are replayed in recorded order, the last one is repeated when all have been used.
+type Recorder struct {
+	// Transport sends the requests in ModeRecord. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	path     string
+	mode     Mode
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder returns a recorder for the cassette at path. In ModeReplay, the cassette must exist.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode == ModeReplay {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = *cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Client returns an http.Client using the recorder as transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// RoundTrip implements http.RoundTripper. It does not modify req, forwarded requests are clones with their own body.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var form []byte
+	if req.Body != nil {
+		var err error
+		form, err = ioutil.ReadAll(req.Body)
+		// A RoundTripper must close the request body, even on errors.
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	scrub := scrubber(req)
+
+	if r.mode == ModeReplay {
+		interaction, err := r.replay(req.Method, scrub(req.URL.String()), scrub(string(form)))
+		if err != nil {
+			return nil, err
+		}
+		return interaction.response(req), nil
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	forward := req.Clone(req.Context())
+	if req.Body != nil {
+		forward.Body = ioutil.NopCloser(bytes.NewReader(form))
+	}
+	response, err := transport.RoundTrip(forward)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Method:      req.Method,
+		URL:         scrub(req.URL.String()),
+		Form:        scrub(string(form)),
+		Status:      response.StatusCode,
+		ContentType: response.Header.Get("Content-Type"),
+		Body:        scrub(string(body)),
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	response.Request = req
+	return response, nil
+}
+
+// replay returns the next recorded interaction matching the request.
+func (r *Recorder) replay(method, rawURL, form string) (Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := -1
+	for i, interaction := range r.cassette.Interactions {
+		if interaction.Method != method || !samePath(interaction.URL, rawURL) || interaction.Form != form {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction, nil
+		}
+		last = i
+	}
+	if last < 0 {
+		return Interaction{}, fmt.Errorf("apitest: no recorded interaction for %s %s", method, rawURL)
+	}
+	return r.cassette.Interactions[last], nil
+}
+
+// Save writes the cassette to its file, creating missing directories.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("apitest: invalid cassette %s: %v", path, err)
+	}
+	return &cassette, nil
+}
+
+func (i Interaction) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if i.ContentType != "" {
+		header.Set("Content-Type", i.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		StatusCode:    i.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(i.Body)),
+		ContentLength: int64(len(i.Body)),
+		Request:       req,
+	}
+}
+
+// samePath reports whether two URLs are equal, ignoring scheme and host.
+func samePath(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Path == ub.Path && ua.RawQuery == ub.RawQuery
+}
+
+// scrubber returns a function replacing the token used by req, taken from the URL path or the Authorization header.
+func scrubber(req *http.Request) func(string) string {
+	var token string
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	segments := strings.Split(req.URL.Path, "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "api_token" {
+			token = segments[i+1]
+		}
+	}
+	return func(s string) string {
+		if token == "" || token == scrubbed {
+			return s
+		}
+		return strings.ReplaceAll(s, token, scrubbed)
+	}
+}
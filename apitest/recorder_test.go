@@ -0,0 +1,119 @@
+package apitest_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rupkoe/timecamp-api"
+	"github.com/rupkoe/timecamp-api/apitest"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "tasks.json")
+	params := api.TimeEntryParams{From: day(1), To: day(31)}
+
+	server := apitest.NewServer()
+	server.AddTask(api.Task{Name: "Project"})
+	server.AddTimeEntry(api.TimeEntry{Date: day(4), Duration: time.Hour})
+
+	recorder, err := apitest.NewRecorder(path, apitest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	c := server.Connection()
+	c.HTTPClient = recorder.Client()
+	wantTasks, err := api.GetTasks(c, api.TaskParams{})
+	if err != nil {
+		t.Fatalf("GetTasks() error = %v", err)
+	}
+	wantEntries, err := api.GetTimeEntries(c, params)
+	if err != nil {
+		t.Fatalf("GetTimeEntries() error = %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), apitest.Token) {
+		t.Errorf("cassette contains the token:\n%s", data)
+	}
+
+	// The server is gone, so everything must come from the cassette.
+	recorder, err = apitest.NewRecorder(path, apitest.ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	c = api.Connection{ApiUrl: "https://timecamp.invalid", Token: "other-token", HTTPClient: recorder.Client()}
+	tasks, err := api.GetTasks(c, api.TaskParams{})
+	if err != nil || !reflect.DeepEqual(tasks, wantTasks) {
+		t.Errorf("replayed GetTasks() = %+v, %v, want %+v", tasks, err, wantTasks)
+	}
+	entries, err := api.GetTimeEntries(c, params)
+	if err != nil || !reflect.DeepEqual(entries, wantEntries) {
+		t.Errorf("replayed GetTimeEntries() = %+v, %v, want %+v", entries, err, wantEntries)
+	}
+	if _, err := api.GetTasks(c, api.TaskParams{}); err != nil {
+		t.Errorf("repeated GetTasks() error = %v", err)
+	}
+	if _, err := api.GetUsers(c); err == nil {
+		t.Errorf("GetUsers() without recorded interaction did not fail")
+	}
+}
+
+func TestRecorder_MissingCassette(t *testing.T) {
+	if _, err := apitest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), apitest.ModeReplay); err == nil {
+		t.Errorf("NewRecorder() of missing cassette did not fail")
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRecorder_DoesNotModifyRequest(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	recorder, err := apitest.NewRecorder(filepath.Join(t.TempDir(), "timer.json"), apitest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+
+	body := &closeTracker{Reader: strings.NewReader("action=status")}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/timer/format/json/api_token/"+apitest.Token, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("RoundTrip() status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	if req.Body != body || !body.closed {
+		t.Errorf("RoundTrip() replaced or did not close the request body")
+	}
+	if interactions := recorder.Interactions(); len(interactions) != 1 || interactions[0].Form != "action=status" {
+		t.Errorf("recorded interactions = %+v", interactions)
+	}
+}
@@ -0,0 +1,344 @@
+// Package apitest provides an in-memory fake of the TimeCamp API, to test code using the api package offline.
+//
+//	server := apitest.NewServer()
+//	defer server.Close()
+//	project := server.AddTask(api.Task{Name: "Project"})
+//	server.AddTimeEntry(api.TimeEntry{TaskID: project.TaskID, Date: day, Duration: time.Hour})
+//
+//	tasks, err := api.GetTasks(server.Connection(), api.TaskParams{})
+//
+// The server keeps its state between requests, so written data can be read again.
+// Like TimeCamp, it sends task IDs as strings and time entry IDs as numbers.
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rupkoe/timecamp-api"
+)
+
+// Token is the API token accepted by the server.
+const Token = "apitest-token"
+
+// Server is a stateful fake TimeCamp API. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	nextID    api.ID
+	tasks     map[api.ID]api.Task
+	entries   map[api.ID]api.TimeEntry
+	users     []api.User
+	groups    []api.Group
+	tagLists  []api.TagList
+	entryTags map[api.ID][]api.Tag
+	timer     *api.Timer
+	timerNote string
+	failures  []Failure
+	requests  []Request
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method   string
+	Resource string
+	// Params holds the parameters from the URL path and the form body.
+	Params map[string]string
+}
+
+// Failure makes the server answer matching requests with an error.
+type Failure struct {
+	// Resource is e.g. "tasks" or "entries". Empty matches all resources.
+	Resource string
+	// Method is e.g. "GET". Empty matches all methods.
+	Method string
+	// Status is the HTTP status of the response. 0 responds with 500 Internal Server Error.
+	Status     int
+	Body       string
+	RetryAfter string
+	// Times is the number of requests to fail. 0 fails a single request.
+	Times int
+}
+
+// NewServer starts a fake TimeCamp API without any data.
+func NewServer() *Server {
+	s := &Server{
+		nextID:    1000,
+		tasks:     make(map[api.ID]api.Task),
+		entries:   make(map[api.ID]api.TimeEntry),
+		entryTags: make(map[api.ID][]api.Tag),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Connection returns a connection to the server.
+func (s *Server) Connection() api.Connection {
+	return api.Connection{ApiUrl: s.URL, Token: Token}
+}
+
+// AddTask stores a task. A missing TaskID is assigned, a missing Level is derived from the parent.
+func (s *Server) AddTask(task api.Task) api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTask(task)
+}
+
+func (s *Server) addTask(task api.Task) api.Task {
+	if task.TaskID == 0 {
+		task.TaskID = s.newID()
+	}
+	if task.Level == 0 {
+		task.Level = 1
+		if parent, ok := s.tasks[task.ParentID]; ok {
+			task.Level = parent.Level + 1
+		}
+	}
+	if task.Users == nil {
+		task.Users = api.TaskUsers{}
+	}
+	s.tasks[task.TaskID] = task
+	return task
+}
+
+// AddTimeEntry stores a time entry. A missing ID is assigned.
+func (s *Server) AddTimeEntry(entry api.TimeEntry) api.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.ID == 0 {
+		entry.ID = s.newID()
+	}
+	s.entryTags[entry.ID] = append([]api.Tag(nil), entry.Tags...)
+	entry.Tags = nil
+	s.entries[entry.ID] = entry
+	entry.Tags = s.tagsOf(entry.ID)
+	return entry
+}
+
+// AddUser stores a user. A missing UserID is assigned.
+func (s *Server) AddUser(user api.User) api.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user.UserID == 0 {
+		user.UserID = s.newID()
+	}
+	s.users = append(s.users, user)
+	return user
+}
+
+// AddGroup stores a group. A missing GroupID is assigned.
+func (s *Server) AddGroup(group api.Group) api.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if group.GroupID == 0 {
+		group.GroupID = s.newID()
+	}
+	s.groups = append(s.groups, group)
+	return group
+}
+
+// AddTagList stores a tag list including its tags. Missing IDs are assigned.
+func (s *Server) AddTagList(list api.TagList) api.TagList {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if list.ID == 0 {
+		list.ID = s.newID()
+	}
+	tags := make([]api.Tag, len(list.Tags))
+	for i, tag := range list.Tags {
+		if tag.ID == 0 {
+			tag.ID = s.newID()
+		}
+		tag.ListID, tag.ListName = list.ID, list.Name
+		tags[i] = tag
+	}
+	list.Tags = tags
+	s.tagLists = append(s.tagLists, list)
+	return list
+}
+
+// Tasks returns all stored tasks ordered by ID.
+func (s *Server) Tasks() []api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tasks []api.Task
+	for _, task := range s.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].TaskID < tasks[j].TaskID })
+	return tasks
+}
+
+// TimeEntries returns all stored time entries ordered by ID, including their tags.
+func (s *Server) TimeEntries() []api.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []api.TimeEntry
+	for _, entry := range s.entries {
+		entry.Tags = s.tagsOf(entry.ID)
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// RunningTimer returns the running timer, nil if none is running.
+func (s *Server) RunningTimer() *api.Timer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer == nil {
+		return nil
+	}
+	timer := *s.timer
+	return &timer
+}
+
+// Fail makes the next matching requests fail.
+func (s *Server) Fail(failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if failure.Times <= 0 {
+		failure.Times = 1
+	}
+	if failure.Status == 0 {
+		failure.Status = http.StatusInternalServerError
+	}
+	s.failures = append(s.failures, failure)
+}
+
+// Requests returns all requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) newID() api.ID {
+	s.nextID++
+	return s.nextID
+}
+
+// serveHTTP parses requests of the form /{resource}/format/json[/api_token/TOKEN][/key/value...].
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 3 || segments[1] != "format" || segments[2] != "json" {
+		httpError(w, http.StatusNotFound, "<html><body>Not Found</body></html>")
+		return
+	}
+	resource := segments[0]
+	params := make(map[string]string)
+	for i := 3; i+1 < len(segments); i += 2 {
+		params[segments[i]] = segments[i+1]
+	}
+	for key, values := range r.URL.Query() {
+		params[key] = values[0]
+	}
+	if err := r.ParseForm(); err == nil {
+		for key, values := range r.PostForm {
+			params[key] = values[0]
+		}
+	}
+	if r.Method == http.MethodDelete {
+		// net/http only parses the body of POST, PUT and PATCH requests.
+		form, err := parseBody(r)
+		if err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for key, value := range form {
+			params[key] = value
+		}
+	}
+
+	token := params["api_token"]
+	delete(params, "api_token")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	s.requests = append(s.requests, Request{Method: r.Method, Resource: resource, Params: params})
+
+	if failure, ok := s.failure(r.Method, resource); ok {
+		if failure.RetryAfter != "" {
+			w.Header().Set("Retry-After", failure.RetryAfter)
+		}
+		httpError(w, failure.Status, failure.Body)
+		return
+	}
+	if token != Token {
+		httpError(w, http.StatusUnauthorized, "<html><body>Unauthorized</body></html>")
+		return
+	}
+
+	handler, ok := map[string]func(string, map[string]string) (interface{}, int, error){
+		"tasks":        s.handleTasks,
+		"entries":      s.handleEntries,
+		"timer":        s.handleTimer,
+		"users":        s.handleUsers,
+		"user":         s.handleUser,
+		"group":        s.handleGroups,
+		"tag_list":     s.handleTagLists,
+		"entries_tags": s.handleEntryTags,
+	}[resource]
+	if !ok {
+		httpError(w, http.StatusNotFound, "<html><body>Not Found</body></html>")
+		return
+	}
+	result, status, err := handler(r.Method, params)
+	if err != nil {
+		httpError(w, status, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// failure returns and consumes the first failure matching the request.
+func (s *Server) failure(method, resource string) (Failure, bool) {
+	for i, f := range s.failures {
+		if (f.Resource == "" || f.Resource == resource) && (f.Method == "" || f.Method == method) {
+			s.failures[i].Times--
+			if s.failures[i].Times <= 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+			return f, true
+		}
+	}
+	return Failure{}, false
+}
+
+func httpError(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprint(w, body)
+}
+
+var (
+	errMethod   = fmt.Errorf("method not allowed")
+	errNotFound = fmt.Errorf("not found")
+)
+
+// methodNotAllowed is returned by handlers for unsupported methods.
+func methodNotAllowed() (interface{}, int, error) {
+	return nil, http.StatusMethodNotAllowed, errMethod
+}
+
+func notFound() (interface{}, int, error) {
+	return nil, http.StatusNotFound, errNotFound
+}
+
+func badRequest(format string, args ...interface{}) (interface{}, int, error) {
+	return nil, http.StatusBadRequest, fmt.Errorf(format, args...)
+}
+
+// now is the time used for timers.
+var now = time.Now
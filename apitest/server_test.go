@@ -0,0 +1,353 @@
+package apitest_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rupkoe/timecamp-api"
+	"github.com/rupkoe/timecamp-api/apitest"
+)
+
+func day(d int) time.Time {
+	return time.Date(2021, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func taskIDs(tasks []api.Task) []api.ID {
+	var ids []api.ID
+	for _, task := range tasks {
+		ids = append(ids, task.TaskID)
+	}
+	return ids
+}
+
+func entryIDs(entries []api.TimeEntry) []api.ID {
+	var ids []api.ID
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestServer_GetTasks(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	project := server.AddTask(api.Task{Name: "Project"})
+	task := server.AddTask(api.Task{Name: "Task", ParentID: project.TaskID})
+	archived := server.AddTask(api.Task{Name: "Old", ParentID: project.TaskID, Archived: 1})
+
+	tests := []struct {
+		name   string
+		params api.TaskParams
+		want   []api.ID
+	}{
+		{"all", api.TaskParams{}, []api.ID{project.TaskID, task.TaskID, archived.TaskID}},
+		{"active", api.TaskParams{OnlyActiveTasks: true}, []api.ID{project.TaskID, task.TaskID}},
+		{"archived", api.TaskParams{OnlyArchivedTasks: true}, []api.ID{archived.TaskID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := api.GetTasks(server.Connection(), tt.params)
+			if err != nil {
+				t.Fatalf("GetTasks() error = %v", err)
+			}
+			if got := taskIDs(tasks); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTasks() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tasks, _ := api.GetTasks(server.Connection(), api.TaskParams{})
+	if tasks[1].ParentID != project.TaskID || tasks[1].Level != 2 {
+		t.Errorf("GetTasks() subtask = %+v, want parent %v on level 2", tasks[1], project.TaskID)
+	}
+}
+
+func TestServer_WriteTasks(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	c := server.Connection()
+
+	project, err := api.CreateTask(c, api.Task{Name: "Project"})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	task, err := api.CreateTask(c, api.Task{Name: "Task", ParentID: project.TaskID})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if task.TaskID == 0 || task.Level != 2 {
+		t.Errorf("CreateTask() got = %+v, want new ID on level 2", task)
+	}
+
+	task.Name = "Renamed"
+	task.ParentID = 0
+	if err := api.UpdateTask(c, task); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if err := api.ArchiveTask(c, project.TaskID); err != nil {
+		t.Fatalf("ArchiveTask() error = %v", err)
+	}
+
+	tasks := server.Tasks()
+	if tasks[0].Archived != 1 {
+		t.Errorf("archived task = %+v", tasks[0])
+	}
+	if tasks[1].Name != "Renamed" || tasks[1].ParentID != 0 || tasks[1].Level != 1 {
+		t.Errorf("updated task = %+v", tasks[1])
+	}
+
+	err = api.UpdateTask(c, api.Task{TaskID: 1, Name: "Missing"})
+	if !api.IsNotFound(err) {
+		t.Errorf("UpdateTask() of missing task error = %v, want not found", err)
+	}
+}
+
+func TestServer_MoveTaskBelowItself(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	c := server.Connection()
+	project := server.AddTask(api.Task{Name: "Project"})
+	task := server.AddTask(api.Task{Name: "Task", ParentID: project.TaskID})
+	subtask := server.AddTask(api.Task{Name: "Subtask", ParentID: task.TaskID})
+
+	tests := []struct {
+		name   string
+		parent api.ID
+	}{
+		{"itself", task.TaskID},
+		{"subtask", subtask.TaskID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moved := task
+			moved.ParentID = tt.parent
+			err := api.UpdateTask(c, moved)
+			var apiErr *api.Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+				t.Errorf("UpdateTask() error = %v, want bad request", err)
+			}
+		})
+	}
+	if tasks := server.Tasks(); tasks[1].ParentID != project.TaskID || tasks[2].Level != 3 {
+		t.Errorf("tasks after rejected moves = %+v", tasks)
+	}
+}
+
+func TestServer_GetTimeEntries(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	project := server.AddTask(api.Task{Name: "Project"})
+	task := server.AddTask(api.Task{Name: "Task", ParentID: project.TaskID})
+	other := server.AddTask(api.Task{Name: "Other"})
+	list := server.AddTagList(api.TagList{Name: "Kind", Tags: []api.Tag{{Name: "Meeting"}}})
+
+	e1 := server.AddTimeEntry(api.TimeEntry{TaskID: project.TaskID, UserID: 1, Date: day(4), Duration: time.Hour})
+	e2 := server.AddTimeEntry(api.TimeEntry{TaskID: task.TaskID, UserID: 2, Date: day(5), Duration: time.Hour,
+		Tags: list.Tags})
+	e3 := server.AddTimeEntry(api.TimeEntry{TaskID: other.TaskID, UserID: 1, Date: day(20), Duration: time.Hour})
+
+	tests := []struct {
+		name   string
+		params api.TimeEntryParams
+		want   []api.ID
+	}{
+		{"range", api.TimeEntryParams{From: day(1), To: day(5)}, []api.ID{e1.ID, e2.ID}},
+		{"single day", api.TimeEntryParams{From: day(20), To: day(20)}, []api.ID{e3.ID}},
+		{"task", api.TimeEntryParams{From: day(1), To: day(31), Tasks: []api.Task{project}}, []api.ID{e1.ID}},
+		{"subtasks", api.TimeEntryParams{From: day(1), To: day(31), Tasks: []api.Task{project}, WithSubtasks: true},
+			[]api.ID{e1.ID, e2.ID}},
+		{"user", api.TimeEntryParams{From: day(1), To: day(31), Users: []api.User{{UserID: 1}}}, []api.ID{e1.ID, e3.ID}},
+		{"windows", api.TimeEntryParams{From: day(1), To: day(31), Window: api.WindowWeek}, []api.ID{e1.ID, e2.ID, e3.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := api.GetTimeEntries(server.Connection(), tt.params)
+			if err != nil {
+				t.Fatalf("GetTimeEntries() error = %v", err)
+			}
+			if got := entryIDs(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTimeEntries() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	entries, err := api.GetTimeEntries(server.Connection(), api.TimeEntryParams{From: day(5), To: day(5), WithTags: true})
+	if err != nil {
+		t.Fatalf("GetTimeEntries() error = %v", err)
+	}
+	if len(entries) != 1 || !entries[0].HasTag("Meeting") || entries[0].Duration != time.Hour {
+		t.Errorf("GetTimeEntries() with tags got = %+v", entries)
+	}
+}
+
+func TestServer_WriteTimeEntries(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	c := server.Connection()
+	task := server.AddTask(api.Task{Name: "Task"})
+
+	id, err := api.CreateTimeEntry(c, api.TimeEntryRequest{Date: day(4), Duration: time.Hour, TaskID: task.TaskID, Note: "coding"})
+	if err != nil {
+		t.Fatalf("CreateTimeEntry() error = %v", err)
+	}
+	err = api.UpdateTimeEntry(c, id, api.TimeEntryRequest{Date: day(5), Duration: 2 * time.Hour, TaskID: task.TaskID})
+	if err != nil {
+		t.Fatalf("UpdateTimeEntry() error = %v", err)
+	}
+
+	entries := server.TimeEntries()
+	if len(entries) != 1 || entries[0].ID != id || !entries[0].Date.Equal(day(5)) || entries[0].Duration != 2*time.Hour {
+		t.Errorf("stored entries = %+v", entries)
+	}
+
+	if err := api.DeleteTimeEntry(c, id); err != nil {
+		t.Fatalf("DeleteTimeEntry() error = %v", err)
+	}
+	if entries := server.TimeEntries(); len(entries) != 0 {
+		t.Errorf("stored entries after delete = %+v", entries)
+	}
+	if err := api.DeleteTimeEntry(c, id); !api.IsNotFound(err) {
+		t.Errorf("DeleteTimeEntry() of missing entry error = %v, want not found", err)
+	}
+}
+
+func TestServer_Timer(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	c := server.Connection()
+	task := server.AddTask(api.Task{Name: "Task"})
+
+	started, err := api.StartTimer(c, task.TaskID, "coding")
+	if err != nil {
+		t.Fatalf("StartTimer() error = %v", err)
+	}
+	running, err := api.GetRunningTimer(c)
+	if err != nil || running == nil {
+		t.Fatalf("GetRunningTimer() = %v, %v", running, err)
+	}
+	if running.TimerID != started.TimerID || running.TaskID != task.TaskID || running.StartedAt.IsZero() {
+		t.Errorf("GetRunningTimer() got = %+v, want %+v", running, started)
+	}
+
+	stopped, err := api.StopTimer(c)
+	if err != nil {
+		t.Fatalf("StopTimer() error = %v", err)
+	}
+	if stopped.EntryID != started.EntryID {
+		t.Errorf("StopTimer() got = %+v, want entry %v", stopped, started.EntryID)
+	}
+	if timer, err := api.GetRunningTimer(c); err != nil || timer != nil {
+		t.Errorf("GetRunningTimer() after stop = %v, %v", timer, err)
+	}
+	entries := server.TimeEntries()
+	if len(entries) != 1 || entries[0].ID != started.EntryID || entries[0].Description != "coding" {
+		t.Errorf("stored entries = %+v", entries)
+	}
+}
+
+func TestServer_UsersAndGroups(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	c := server.Connection()
+	group := server.AddGroup(api.Group{Name: "Team"})
+	user := server.AddUser(api.User{GroupID: group.GroupID, Email: "a@example.com", DisplayName: "A", Role: api.RoleUser})
+
+	users, err := api.GetUsers(c)
+	if err != nil {
+		t.Fatalf("GetUsers() error = %v", err)
+	}
+	if !reflect.DeepEqual(users, []api.User{user}) {
+		t.Errorf("GetUsers() got = %+v, want %+v", users, user)
+	}
+
+	got, err := api.GetUser(c, user.UserID)
+	if err != nil || got != user {
+		t.Errorf("GetUser() = %+v, %v, want %+v", got, err, user)
+	}
+	if _, err := api.GetUser(c, 1); !api.IsNotFound(err) {
+		t.Errorf("GetUser() of missing user error = %v, want not found", err)
+	}
+
+	groups, err := api.GetGroups(c)
+	if err != nil || !reflect.DeepEqual(groups, []api.Group{group}) {
+		t.Errorf("GetGroups() = %+v, %v, want %+v", groups, err, group)
+	}
+}
+
+func TestServer_Tags(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	c := server.Connection()
+	list := server.AddTagList(api.TagList{Name: "Kind", Tags: []api.Tag{{Name: "Meeting"}, {Name: "Travel"}}})
+	entry := server.AddTimeEntry(api.TimeEntry{Date: day(4), Duration: time.Hour})
+
+	lists, err := api.GetTagLists(c)
+	if err != nil || len(lists) != 1 || lists[0].ID != list.ID || lists[0].Name != "Kind" {
+		t.Errorf("GetTagLists() = %+v, %v", lists, err)
+	}
+	tags, err := api.GetTags(c, list.ID)
+	if err != nil || len(tags) != 2 {
+		t.Errorf("GetTags() = %+v, %v", tags, err)
+	}
+
+	if err := api.AddTimeEntryTags(c, entry.ID, list.Tags[0].ID, list.Tags[1].ID); err != nil {
+		t.Fatalf("AddTimeEntryTags() error = %v", err)
+	}
+	if err := api.RemoveTimeEntryTags(c, entry.ID, list.Tags[1].ID); err != nil {
+		t.Fatalf("RemoveTimeEntryTags() error = %v", err)
+	}
+	entryTags, err := api.GetTimeEntryTags(c, entry.ID)
+	if err != nil {
+		t.Fatalf("GetTimeEntryTags() error = %v", err)
+	}
+	if want := map[api.ID][]api.Tag{entry.ID: {list.Tags[0]}}; !reflect.DeepEqual(entryTags, want) {
+		t.Errorf("GetTimeEntryTags() got = %+v, want %+v", entryTags, want)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+
+	c := server.Connection()
+	c.Auth = api.AuthHeader
+	if _, err := api.GetTasks(c, api.TaskParams{}); err != nil {
+		t.Errorf("GetTasks() with header auth error = %v", err)
+	}
+
+	c.Token = "wrong"
+	if _, err := api.GetTasks(c, api.TaskParams{}); !api.IsUnauthorized(err) {
+		t.Errorf("GetTasks() with wrong token error = %v, want unauthorized", err)
+	}
+}
+
+func TestServer_Fail(t *testing.T) {
+	server := apitest.NewServer()
+	defer server.Close()
+	server.AddTask(api.Task{Name: "Task"})
+
+	server.Fail(apitest.Failure{Resource: "tasks", Status: http.StatusTooManyRequests, RetryAfter: "0", Times: 2})
+	c := server.Connection()
+	if _, err := api.GetTasks(c, api.TaskParams{}); !api.IsRateLimited(err) {
+		t.Errorf("GetTasks() error = %v, want rate limited", err)
+	}
+
+	c.Retry = &api.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	tasks, err := api.GetTasks(c, api.TaskParams{})
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("GetTasks() with retry = %v, %v, want 1 task", tasks, err)
+	}
+	if requests := server.Requests(); len(requests) != 3 {
+		t.Errorf("server received %d requests, want 3", len(requests))
+	}
+
+	server.Fail(apitest.Failure{Resource: "tasks"})
+	_, err = api.GetTasks(server.Connection(), api.TaskParams{})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("GetTasks() without failure status error = %v, want internal server error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AuthMode selects how the API token is sent to TimeCamp.
+type AuthMode int
+
+const (
+	// AuthURL embeds the token in the URL path (.../api_token/TOKEN). This is the default.
+	AuthURL AuthMode = iota
+	// AuthHeader sends the token in the Authorization header, keeping it out of URLs and logs.
+	AuthHeader
+)
+
+// redacted replaces the API token in URLs and error messages.
+const redacted = "REDACTED"
+
+// endpointUrl returns the base URL for an api resource, e.g. ".../tasks/format/json/api_token/TOKEN".
+func endpointUrl(c Connection, resource string) string {
+	u := c.ApiUrl + "/" + resource + "/format/json"
+	if c.Auth == AuthURL {
+		u += "/api_token/" + c.Token
+	}
+	return u
+}
+
+// authorize adds the token to the request if it is not part of the URL.
+func authorize(c Connection, request *http.Request) {
+	if c.Auth == AuthHeader {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+// Redact replaces all occurrences of the connection's API token in s.
+func (c Connection) Redact(s string) string {
+	if c.Token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, c.Token, redacted)
+}
+
+// redactErrorBody redacts the token from an error body read up to maxErrorBody+len(c.Token)+1 bytes.
+// The body is cut after byte maxErrorBody, or after a token crossing it, before redacting. Otherwise a partially
+// read token at the end would not be redacted and could move into the bytes kept by newError.
+func (c Connection) redactErrorBody(body []byte) []byte {
+	end := len(body)
+	if end > maxErrorBody+1 {
+		end = maxErrorBody + 1
+		start := end - len(c.Token)
+		if start < 0 {
+			start = 0
+		}
+		if i := bytes.Index(body[start:], []byte(c.Token)); c.Token != "" && i >= 0 && start+i < end {
+			end = start + i + len(c.Token)
+		}
+	}
+	return []byte(c.Redact(string(body[:end])))
+}
+
+// redactError makes sure the error message of err does not contain the API token.
+func redactError(c Connection, err error) error {
+	if err == nil || c.Token == "" {
+		return err
+	}
+	if urlErr, ok := err.(*url.Error); ok {
+		return &url.Error{Op: urlErr.Op, URL: c.Redact(urlErr.URL), Err: redactError(c, urlErr.Err)}
+	}
+	if !strings.Contains(err.Error(), c.Token) {
+		return err
+	}
+	return &redactedError{msg: c.Redact(err.Error()), err: err}
+}
+
+// redactedError hides the token in the message of a wrapped error.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
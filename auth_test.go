@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthHeader(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN", Auth: AuthHeader}
+	if _, err := GetTasks(c, TaskParams{}); err != nil {
+		t.Fatalf("GetTasks() error = %v", err)
+	}
+	if gotPath != "/tasks/format/json" {
+		t.Errorf("request path = %s, want /tasks/format/json", gotPath)
+	}
+	if gotAuth != "Bearer TOKEN" {
+		t.Errorf("Authorization header = %q, want %q", gotAuth, "Bearer TOKEN")
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("no such page: " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		c    Connection
+	}{
+		{name: "Invalid URL", c: Connection{ApiUrl: "http://bad\x7fhost", Token: "SECRET"}},
+		{name: "Network Error", c: Connection{ApiUrl: "http://127.0.0.1:0", Token: "SECRET"}},
+		{name: "API Error", c: Connection{ApiUrl: server.URL, Token: "SECRET"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetTasks(tt.c, TaskParams{})
+			if err == nil {
+				t.Fatal("GetTasks() expected error")
+			}
+			if strings.Contains(err.Error(), "SECRET") {
+				t.Errorf("error message contains token: %v", err)
+			}
+			var apiErr *Error
+			if errors.As(err, &apiErr) && strings.Contains(apiErr.Endpoint+apiErr.Body, "SECRET") {
+				t.Errorf("api error contains token: %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestRedaction_ErrorBody(t *testing.T) {
+	const token = "qz-secret-0123456789abcdef"
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "Token Across Limit", body: strings.Repeat("x", maxErrorBody-2) + token + strings.Repeat("x", 100)},
+		// Redacting the leading tokens shortens the body, the partially read token at the end must not move into it.
+		{name: "Token After Limit", body: strings.Repeat(token, 4) + strings.Repeat("x", maxErrorBody-4*len(token)+len(token)-2) + token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := GetTasks(Connection{ApiUrl: server.URL, Token: token, Auth: AuthHeader}, TaskParams{})
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetTasks() error = %v, want *Error", err)
+			}
+			if strings.Contains(apiErr.Body, token[:2]) || strings.Contains(err.Error(), token[:2]) {
+				t.Errorf("error contains part of the token: %q", apiErr.Body)
+			}
+			if len(apiErr.Body) > maxErrorBody+3 {
+				t.Errorf("len(Body) = %d, want at most %d", len(apiErr.Body), maxErrorBody+3)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BudgetUnit is the unit of a task's budget.
+type BudgetUnit int
+
+const (
+	// BudgetNone means no budget is set.
+	BudgetNone BudgetUnit = iota
+	BudgetHours
+	BudgetMoney
+	BudgetPercentage
+)
+
+func (u BudgetUnit) String() string {
+	switch u {
+	case BudgetNone:
+		return "none"
+	case BudgetHours:
+		return "hours"
+	case BudgetMoney:
+		return "money"
+	case BudgetPercentage:
+		return "percentage"
+	default:
+		return "unit " + strconv.Itoa(int(u))
+	}
+}
+
+// parseBudgetUnit maps the budget_unit values used by TimeCamp.
+func parseBudgetUnit(unit string) (BudgetUnit, error) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "":
+		return BudgetNone, nil
+	case "hours", "hour", "h":
+		return BudgetHours, nil
+	case "fee", "money", "cost":
+		return BudgetMoney, nil
+	case "percentage", "percent", "%":
+		return BudgetPercentage, nil
+	}
+	return BudgetNone, fmt.Errorf("unknown budget unit %q", unit)
+}
+
+// Budget is a task's budget.
+type Budget struct {
+	Unit   BudgetUnit
+	Amount float64
+}
+
+// IsSet is false if the task has no budget.
+func (b Budget) IsSet() bool {
+	return b.Unit != BudgetNone && b.Amount > 0
+}
+
+// Hours returns an hour budget as duration. ok is false for other units.
+func (b Budget) Hours() (hours time.Duration, ok bool) {
+	if b.Unit != BudgetHours {
+		return 0, false
+	}
+	return time.Duration(b.Amount * float64(time.Hour)), true
+}
+
+// Compare returns -1, 0 or 1 if b is less than, equal to or greater than other.
+// Budgets with different units can't be compared.
+func (b Budget) Compare(other Budget) (int, error) {
+	if b.Unit != other.Unit {
+		return 0, fmt.Errorf("cannot compare budget in %s with budget in %s", b.Unit, other.Unit)
+	}
+	switch {
+	case b.Amount < other.Amount:
+		return -1, nil
+	case b.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (b Budget) String() string {
+	if !b.IsSet() {
+		return "no budget"
+	}
+	return strconv.FormatFloat(b.Amount, 'f', -1, 64) + " " + b.Unit.String()
+}
+
+// Budget parses the task's Budgeted and BudgetUnit fields.
+func (t Task) Budget() (Budget, error) {
+	unit, err := parseBudgetUnit(t.BudgetUnit)
+	if err != nil {
+		return Budget{}, fmt.Errorf("task %d: %w", t.TaskID, err)
+	}
+	if t.Budgeted <= 0 {
+		return Budget{}, nil
+	}
+	if unit == BudgetNone {
+		return Budget{}, fmt.Errorf("task %d: budget %d without unit", t.TaskID, t.Budgeted)
+	}
+	return Budget{Unit: unit, Amount: float64(t.Budgeted)}, nil
+}
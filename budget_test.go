@@ -0,0 +1,58 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTask_Budget(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    Task
+		want    Budget
+		wantErr bool
+	}{
+		{name: "No Budget", task: Task{}, want: Budget{}},
+		{name: "Unit Without Amount", task: Task{BudgetUnit: "hours"}, want: Budget{}},
+		{name: "Hours", task: Task{Budgeted: 40, BudgetUnit: "hours"}, want: Budget{Unit: BudgetHours, Amount: 40}},
+		{name: "Money", task: Task{Budgeted: 5000, BudgetUnit: "fee"}, want: Budget{Unit: BudgetMoney, Amount: 5000}},
+		{name: "Percentage", task: Task{Budgeted: 80, BudgetUnit: "Percentage"}, want: Budget{Unit: BudgetPercentage, Amount: 80}},
+		{name: "Amount Without Unit", task: Task{Budgeted: 40}, wantErr: true},
+		{name: "Unknown Unit", task: Task{Budgeted: 40, BudgetUnit: "days"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.task.Budget()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Budget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Budget() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudget_Compare(t *testing.T) {
+	small := Budget{Unit: BudgetHours, Amount: 10}
+	large := Budget{Unit: BudgetHours, Amount: 20}
+	if got, err := small.Compare(large); err != nil || got != -1 {
+		t.Errorf("Compare() = %d, %v, want -1", got, err)
+	}
+	if got, err := large.Compare(small); err != nil || got != 1 {
+		t.Errorf("Compare() = %d, %v, want 1", got, err)
+	}
+	if got, err := small.Compare(small); err != nil || got != 0 {
+		t.Errorf("Compare() = %d, %v, want 0", got, err)
+	}
+	if _, err := small.Compare(Budget{Unit: BudgetMoney, Amount: 10}); err == nil {
+		t.Errorf("Compare() with different units expected error")
+	}
+	if hours, ok := large.Hours(); !ok || hours != 20*time.Hour {
+		t.Errorf("Hours() = %v, %v, want 20h", hours, ok)
+	}
+	if _, ok := (Budget{Unit: BudgetMoney, Amount: 10}).Hours(); ok {
+		t.Errorf("Hours() for money budget should not be ok")
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// defaultConcurrency is the number of parallel requests for chunked time entry queries.
+const defaultConcurrency = 4
+
+// getTimeEntriesChunked fetches the entries of every window of params' range in parallel and merges them.
+func getTimeEntriesChunked(ctx context.Context, con Connection, params TimeEntryParams) ([]TimeEntry, error) {
+	// Validate all params once instead of failing in every request.
+	if _, err := timeEntryUrl(con, params); err != nil {
+		return nil, err
+	}
+	windows := params.Range().Split(params.Window)
+
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		results  = make([][]TimeEntry, len(windows))
+		slots    = make(chan struct{}, concurrency)
+	)
+	for i, window := range windows {
+		wg.Add(1)
+		go func(i int, window DateRange) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			windowParams := params
+			windowParams.From, windowParams.To = window.From, window.To
+			windowParams.Window = WindowNone
+			entries, err := GetTimeEntriesContext(ctx, con, windowParams)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = entries
+		}(i, window)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mergeTimeEntries(results), nil
+}
+
+// mergeTimeEntries concatenates entries, dropping duplicates of non-zero IDs, and sorts them by date, start time and ID.
+func mergeTimeEntries(parts [][]TimeEntry) []TimeEntry {
+	merged := []TimeEntry{}
+	seen := make(map[ID]bool)
+	for _, entries := range parts {
+		for _, entry := range entries {
+			// Entries without ID cannot be told apart, keep all of them.
+			if entry.ID != 0 {
+				if seen[entry.ID] {
+					continue
+				}
+				seen[entry.ID] = true
+			}
+			merged = append(merged, entry)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i], merged[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		return a.ID < b.ID
+	})
+	return merged
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetTimeEntries_Window(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		requests  []string
+		active    int
+		maxActive int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+		time.Sleep(5 * time.Millisecond)
+
+		// .../from/2021-01-01/to/2021-01-31/task_ids/
+		parts := strings.Split(r.URL.Path, "/")
+		from := parts[len(parts)-5]
+		mu.Lock()
+		requests = append(requests, from)
+		mu.Unlock()
+
+		var entries []TimeEntry
+		switch from {
+		case "2021-01-01":
+			entries = []TimeEntry{
+				{ID: 2, Date: time.Date(2021, 01, 20, 0, 0, 0, 0, time.UTC)},
+				{ID: 1, Date: time.Date(2021, 01, 10, 0, 0, 0, 0, time.UTC)},
+			}
+		case "2021-02-01":
+			// TimeCamp might return entries spanning the window boundary twice.
+			entries = []TimeEntry{
+				{ID: 2, Date: time.Date(2021, 01, 20, 0, 0, 0, 0, time.UTC)},
+				{ID: 3, Date: time.Date(2021, 02, 01, 0, 0, 0, 0, time.UTC)},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	params := DateRange{From: time.Date(2021, 01, 01, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)}.Params()
+	params.Window = WindowMonth
+	params.Concurrency = 3
+	entries, err := GetTimeEntries(c, params)
+	if err != nil {
+		t.Fatalf("GetTimeEntries() error = %v", err)
+	}
+
+	if len(requests) != 12 {
+		t.Errorf("server received %d requests, want 12", len(requests))
+	}
+	if maxActive > 3 {
+		t.Errorf("%d parallel requests, want at most 3", maxActive)
+	}
+	var ids []ID
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Errorf("GetTimeEntries() returned IDs %v, want [1 2 3]", ids)
+	}
+}
+
+func TestGetTimeEntries_WindowError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/from/2021-03-01/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	params := DateRange{From: time.Date(2021, 01, 01, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 06, 30, 0, 0, 0, 0, time.UTC)}.Params()
+	params.Window = WindowMonth
+	if _, err := GetTimeEntries(c, params); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("GetTimeEntries() error = %v, want server error", err)
+	}
+}
+
+func TestMergeTimeEntries(t *testing.T) {
+	day := time.Date(2021, 01, 04, 0, 0, 0, 0, time.UTC)
+	merged := mergeTimeEntries([][]TimeEntry{
+		{{ID: 1, Date: day}, {ID: 0, Date: day, Description: "a"}},
+		{{ID: 1, Date: day}, {ID: 0, Date: day, Description: "b"}},
+	})
+
+	var got []string
+	for _, entry := range merged {
+		got = append(got, entry.ID.String()+entry.Description)
+	}
+	if want := []string{"0a", "0b", "1"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("mergeTimeEntries() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateRange is a range of whole days, including both From and To.
+// Only the calendar day of From and To counts, time of day is ignored.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Today returns the range of the current day in loc. A nil loc means UTC.
+func Today(loc *time.Location) DateRange {
+	today := midnight(now().In(location(loc)))
+	return DateRange{From: today, To: today}
+}
+
+// ThisWeek returns the range from Monday to Sunday of the current week in loc. A nil loc means UTC.
+func ThisWeek(loc *time.Location) DateRange {
+	today := midnight(now().In(location(loc)))
+	// time.Weekday starts the week on Sunday.
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	return DateRange{From: monday, To: monday.AddDate(0, 0, 6)}
+}
+
+// LastWeek returns the range from Monday to Sunday of the previous week in loc. A nil loc means UTC.
+func LastWeek(loc *time.Location) DateRange {
+	week := ThisWeek(loc)
+	return DateRange{From: week.From.AddDate(0, 0, -7), To: week.To.AddDate(0, 0, -7)}
+}
+
+// ThisMonth returns the range of the current month in loc. A nil loc means UTC.
+func ThisMonth(loc *time.Location) DateRange {
+	return monthOf(now().In(location(loc)))
+}
+
+// LastMonth returns the range of the previous month in loc. A nil loc means UTC.
+func LastMonth(loc *time.Location) DateRange {
+	thisMonth := ThisMonth(loc)
+	return monthOf(thisMonth.From.AddDate(0, 0, -1))
+}
+
+// Validate returns an error if To is on a day before From.
+func (r DateRange) Validate() error {
+	if day(r.To).Before(day(r.From)) {
+		return fmt.Errorf("date range: To date %s must not be before From date %s",
+			r.To.Format(DateFormat), r.From.Format(DateFormat))
+	}
+	return nil
+}
+
+// Contains is true if the calendar day of t is within the range.
+func (r DateRange) Contains(t time.Time) bool {
+	d := day(t)
+	return !d.Before(day(r.From)) && !d.After(day(r.To))
+}
+
+// Days returns the number of days in the range, 0 if To is before From.
+func (r DateRange) Days() int {
+	from, to := day(r.From), day(r.To)
+	if to.Before(from) {
+		return 0
+	}
+	// Calendar arithmetic in UTC is not affected by daylight saving time.
+	return int(to.Sub(from)/(24*time.Hour)) + 1
+}
+
+// Params returns TimeEntryParams for all entries within the range.
+func (r DateRange) Params() TimeEntryParams {
+	return TimeEntryParams{From: r.From, To: r.To}
+}
+
+// Range returns the date range of the params.
+func (p TimeEntryParams) Range() DateRange {
+	return DateRange{From: p.From, To: p.To}
+}
+
+// Window is the size of the parts a DateRange is split into.
+type Window int
+
+const (
+	// WindowNone does not split the range.
+	WindowNone Window = iota
+	WindowDay
+	WindowWeek
+	WindowMonth
+	WindowYear
+)
+
+// Split divides the range into consecutive parts aligned to calendar days, weeks (starting Monday), months or years.
+// The first and last part are cut to the range. An invalid range returns no parts.
+func (r DateRange) Split(w Window) []DateRange {
+	if r.Validate() != nil {
+		return nil
+	}
+	from := midnight(r.From)
+	to := time.Date(r.To.Year(), r.To.Month(), r.To.Day(), 0, 0, 0, 0, from.Location())
+	if w == WindowNone {
+		return []DateRange{{From: from, To: to}}
+	}
+
+	var parts []DateRange
+	for start := from; !start.After(to); {
+		end := w.lastDay(start)
+		if end.After(to) {
+			end = to
+		}
+		parts = append(parts, DateRange{From: start, To: end})
+		start = end.AddDate(0, 0, 1)
+	}
+	return parts
+}
+
+// lastDay returns the last day of the window starting at or containing d.
+func (w Window) lastDay(d time.Time) time.Time {
+	switch w {
+	case WindowWeek:
+		return d.AddDate(0, 0, 6-(int(d.Weekday())+6)%7)
+	case WindowMonth:
+		return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location())
+	case WindowYear:
+		return time.Date(d.Year(), time.December, 31, 0, 0, 0, 0, d.Location())
+	default:
+		return d
+	}
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+func location(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// day returns t's calendar day as midnight UTC, to compare days of times in different locations.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// midnight returns the start of t's day in t's location.
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func monthOf(t time.Time) DateRange {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return DateRange{From: first, To: first.AddDate(0, 1, -1)}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDateRange_Helpers(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	// Sunday 2021-01-31 23:30 UTC is already Monday 2021-02-01 in Berlin.
+	defer func(n func() time.Time) { now = n }(now)
+	now = func() time.Time { return time.Date(2021, 01, 31, 23, 30, 0, 0, time.UTC) }
+
+	date := func(y int, m time.Month, d int, loc *time.Location) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+	tests := []struct {
+		name string
+		got  DateRange
+		want DateRange
+	}{
+		{name: "Today UTC", got: Today(nil), want: DateRange{date(2021, 1, 31, time.UTC), date(2021, 1, 31, time.UTC)}},
+		{name: "Today Berlin", got: Today(berlin), want: DateRange{date(2021, 2, 1, berlin), date(2021, 2, 1, berlin)}},
+		{name: "This Week UTC", got: ThisWeek(nil), want: DateRange{date(2021, 1, 25, time.UTC), date(2021, 1, 31, time.UTC)}},
+		{name: "This Week Berlin", got: ThisWeek(berlin), want: DateRange{date(2021, 2, 1, berlin), date(2021, 2, 7, berlin)}},
+		{name: "Last Week UTC", got: LastWeek(nil), want: DateRange{date(2021, 1, 18, time.UTC), date(2021, 1, 24, time.UTC)}},
+		{name: "This Month Berlin", got: ThisMonth(berlin), want: DateRange{date(2021, 2, 1, berlin), date(2021, 2, 28, berlin)}},
+		{name: "Last Month UTC", got: LastMonth(nil), want: DateRange{date(2020, 12, 1, time.UTC), date(2020, 12, 31, time.UTC)}},
+		{name: "Last Month Berlin", got: LastMonth(berlin), want: DateRange{date(2021, 1, 1, berlin), date(2021, 1, 31, berlin)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.From.Equal(tt.want.From) || !tt.got.To.Equal(tt.want.To) {
+				t.Errorf("got %v - %v, want %v - %v", tt.got.From, tt.got.To, tt.want.From, tt.want.To)
+			}
+		})
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	r := DateRange{
+		From: time.Date(2021, 01, 30, 18, 0, 0, 0, time.UTC),
+		To:   time.Date(2021, 02, 02, 6, 0, 0, 0, time.UTC),
+	}
+	if err := r.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if got := r.Days(); got != 4 {
+		t.Errorf("Days() = %d, want 4", got)
+	}
+	for _, contained := range []time.Time{
+		time.Date(2021, 01, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 02, 02, 23, 59, 0, 0, time.UTC),
+	} {
+		if !r.Contains(contained) {
+			t.Errorf("Contains(%v) = false", contained)
+		}
+	}
+	if r.Contains(time.Date(2021, 02, 03, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Contains() day after To = true")
+	}
+
+	reversed := DateRange{From: r.To, To: r.From}
+	if err := reversed.Validate(); err == nil {
+		t.Errorf("Validate() expected error for reversed range")
+	}
+	if got := reversed.Days(); got != 0 {
+		t.Errorf("Days() of reversed range = %d, want 0", got)
+	}
+	if got := r.Params().Range(); got != r {
+		t.Errorf("Params().Range() = %v, want %v", got, r)
+	}
+}
+
+func TestDateRange_Split(t *testing.T) {
+	date := func(m time.Month, d int) time.Time {
+		return time.Date(2021, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	r := DateRange{From: time.Date(2021, 01, 28, 15, 0, 0, 0, time.UTC), To: date(3, 2)}
+	tests := []struct {
+		name   string
+		window Window
+		want   []DateRange
+	}{
+		{name: "None", window: WindowNone, want: []DateRange{{date(1, 28), date(3, 2)}}},
+		{name: "Month", window: WindowMonth, want: []DateRange{
+			{date(1, 28), date(1, 31)},
+			{date(2, 1), date(2, 28)},
+			{date(3, 1), date(3, 2)},
+		}},
+		{name: "Week", window: WindowWeek, want: []DateRange{
+			{date(1, 28), date(1, 31)},
+			{date(2, 1), date(2, 7)},
+			{date(2, 8), date(2, 14)},
+			{date(2, 15), date(2, 21)},
+			{date(2, 22), date(2, 28)},
+			{date(3, 1), date(3, 2)},
+		}},
+		{name: "Year", window: WindowYear, want: []DateRange{{date(1, 28), date(3, 2)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Split(tt.window)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Split() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].From.Equal(tt.want[i].From) || !got[i].To.Equal(tt.want[i].To) {
+					t.Errorf("Split()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if got := (DateRange{From: date(3, 1), To: date(3, 1)}).Split(WindowDay); len(got) != 1 {
+		t.Errorf("Split() of single day = %v", got)
+	}
+	if got := (DateRange{From: date(3, 2), To: date(3, 1)}).Split(WindowDay); got != nil {
+		t.Errorf("Split() of reversed range = %v, want nil", got)
+	}
+}
+
+func TestGetTimeEntriesInRange(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	r := DateRange{From: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC)}
+	// From and To of the params are replaced by the range.
+	params := TimeEntryParams{From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), WithTags: true}
+	if _, err := GetTimeEntriesInRange(c, r, params); err != nil {
+		t.Fatalf("GetTimeEntriesInRange() error = %v", err)
+	}
+	want := "/entries/format/json/api_token/TOKEN/from/2021-01-04/to/2021-01-10/task_ids//opt_fields/tags"
+	if len(paths) != 1 || paths[0] != want {
+		t.Errorf("GetTimeEntriesInRange() requested %v, want %v", paths, want)
+	}
+
+	reversed := DateRange{From: r.To, To: r.From}
+	if _, err := GetTimeEntriesInRange(c, reversed, TimeEntryParams{}); err == nil {
+		t.Errorf("GetTimeEntriesInRange() expected error for reversed range")
+	}
+}
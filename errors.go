@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBody is the number of bytes of a response body kept in Error.Body.
+const maxErrorBody = 512
+
+// Error is returned when the TimeCamp API responds with a non-2xx status code.
+type Error struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	// Body holds the beginning of the response body, truncated to a few hundred bytes.
+	Body string
+	// RetryAfter is the delay requested by the Retry-After header, 0 if none was sent.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("timecamp api: %s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if body := strings.TrimSpace(e.Body); body != "" {
+		msg += ": " + body
+	}
+	return msg
+}
+
+// DecodeError reports a value in TimeCamp's JSON that could not be converted to its Go type.
+type DecodeError struct {
+	// Object is the kind of object being decoded, e.g. "time entry".
+	Object string
+	// ID identifies the object, empty if unknown.
+	ID    string
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	object := e.Object
+	if e.ID != "" {
+		object += " " + e.ID
+	}
+	return fmt.Sprintf("decoding %s: field %q: invalid value %s: %v", object, e.Field, e.Value, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnauthorized is true if err is an API error caused by a missing, invalid or insufficient token.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// IsRateLimited is true if err is an API error caused by too many requests.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsNotFound is true if err is an API error caused by an unknown endpoint or resource.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// newError creates an Error from a non-2xx response and the (possibly partial) body read from it.
+func newError(response *http.Response, endpoint string, body []byte) *Error {
+	if len(body) > maxErrorBody {
+		body = append(body[:maxErrorBody:maxErrorBody], "..."...)
+	}
+	return &Error{
+		StatusCode: response.StatusCode,
+		Method:     response.Request.Method,
+		Endpoint:   endpoint,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: delay in seconds and HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
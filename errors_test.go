@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "Missing", value: "", want: 0},
+		{name: "Seconds", value: "120", want: 2 * time.Minute},
+		{name: "Negative Seconds", value: "-5", want: 0},
+		{name: "HTTP Date", value: "Fri, 01 Jan 2021 12:00:30 GMT", want: 30 * time.Second},
+		{name: "HTTP Date In The Past", value: "Fri, 01 Jan 2021 11:00:00 GMT", want: 0},
+		{name: "Garbage", value: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		retryAfter       string
+		body             string
+		wantUnauthorized bool
+		wantRateLimited  bool
+		wantRetryAfter   time.Duration
+	}{
+		{
+			name:             "Unauthorized",
+			status:           http.StatusUnauthorized,
+			body:             "<html>Unauthorized</html>",
+			wantUnauthorized: true,
+		}, {
+			name:             "Forbidden",
+			status:           http.StatusForbidden,
+			body:             "<html>Forbidden</html>",
+			wantUnauthorized: true,
+		}, {
+			name:            "Rate Limited",
+			status:          http.StatusTooManyRequests,
+			retryAfter:      "7",
+			body:            "slow down",
+			wantRateLimited: true,
+			wantRetryAfter:  7 * time.Second,
+		}, {
+			name:   "Server Error",
+			status: http.StatusInternalServerError,
+			body:   strings.Repeat("x", 2*maxErrorBody),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := GetTasks(Connection{ApiUrl: server.URL, Token: "TOKEN"}, TaskParams{})
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetTasks() error = %v, want *Error", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if apiErr.Method != http.MethodGet || !strings.Contains(apiErr.Endpoint, "/tasks/") {
+				t.Errorf("unexpected request info %s %s", apiErr.Method, apiErr.Endpoint)
+			}
+			if len(apiErr.Body) > maxErrorBody+3 {
+				t.Errorf("Body not truncated, length %d", len(apiErr.Body))
+			}
+			if apiErr.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("RetryAfter = %v, want %v", apiErr.RetryAfter, tt.wantRetryAfter)
+			}
+			if IsUnauthorized(err) != tt.wantUnauthorized {
+				t.Errorf("IsUnauthorized() = %v, want %v", IsUnauthorized(err), tt.wantUnauthorized)
+			}
+			if IsRateLimited(err) != tt.wantRateLimited {
+				t.Errorf("IsRateLimited() = %v, want %v", IsRateLimited(err), tt.wantRateLimited)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import "strconv"
+
+// ID identifies TimeCamp objects like tasks, time entries and users.
+//
+// TimeCamp is inconsistent in sending IDs as JSON string or number, ID accepts both.
+// Empty strings and null decode to 0. IDs are always encoded as number.
+type ID int
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	return (*flexInt)(id).UnmarshalJSON(data)
+}
+
+func (id ID) MarshalJSON() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id ID) String() string {
+	return strconv.Itoa(int(id))
+}
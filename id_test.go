@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestID_JSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    ID
+		wantErr bool
+	}{
+		{name: "Number", json: `42`, want: 42},
+		{name: "String", json: `"42"`, want: 42},
+		{name: "Empty String", json: `""`, want: 0},
+		{name: "Null", json: `null`, want: 0},
+		{name: "Float", json: `4.2`, wantErr: true},
+		{name: "Text", json: `"abc"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ID
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	data, err := json.Marshal(struct {
+		ID ID `json:"id"`
+	}{42})
+	if err != nil || string(data) != `{"id":42}` {
+		t.Errorf("MarshalJSON() got = %s, %v", data, err)
+	}
+}
+
+func TestTask_IDs(t *testing.T) {
+	var tasks map[string]Task
+	err := json.Unmarshal([]byte(`{"1":{"task_id":"1","parent_id":"0","root_group_id":3},"2":{"task_id":2,"parent_id":1}}`), &tasks)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if tasks["1"].TaskID != 1 || tasks["1"].RootGroupID != 3 || tasks["2"].TaskID != 2 || tasks["2"].ParentID != 1 {
+		t.Errorf("Unmarshal() got = %+v", tasks)
+	}
+}
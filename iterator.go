@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// EntryIterator decodes time entries one at a time while reading the response,
+// so even huge ranges are processed in constant memory.
+//
+//	it := api.IterateTimeEntries(ctx, connection, params)
+//	defer it.Close()
+//	for it.Next() {
+//		entry := it.Entry()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// If params.Window is set, the windows are requested one after the other.
+// Unlike GetTimeEntries, entries are not de-duplicated or sorted then.
+type EntryIterator struct {
+	ctx     context.Context
+	con     Connection
+	params  TimeEntryParams
+	windows []DateRange
+	body    io.ReadCloser
+	decoder *json.Decoder
+	entry   TimeEntry
+	err     error
+}
+
+// IterateTimeEntries returns an iterator over the entries of the "GET /entries" api endpoint.
+// Requests are sent when calling Next, errors are reported by Err.
+func IterateTimeEntries(ctx context.Context, con Connection, params TimeEntryParams) *EntryIterator {
+	it := &EntryIterator{ctx: ctx, con: con, params: params}
+	if _, err := timeEntryUrl(con, params); err != nil {
+		it.err = err
+		return it
+	}
+	it.windows = params.Range().Split(params.Window)
+	return it
+}
+
+// Next decodes the next entry, returning false when all entries were read or an error occurred.
+func (it *EntryIterator) Next() bool {
+	for it.err == nil {
+		if it.decoder == nil {
+			if len(it.windows) == 0 {
+				return false
+			}
+			it.err = it.openWindow(it.windows[0])
+			it.windows = it.windows[1:]
+			continue
+		}
+
+		if it.decoder.More() {
+			var entry TimeEntry
+			if err := it.decoder.Decode(&entry); err != nil {
+				it.fail(err)
+				return false
+			}
+			it.entry = entry
+			return true
+		}
+
+		// Consume the closing bracket of the array, the window is done.
+		if _, err := it.decoder.Token(); err != nil {
+			it.fail(err)
+			return false
+		}
+		it.closeBody()
+	}
+	return false
+}
+
+// Entry returns the entry decoded by the last call to Next.
+func (it *EntryIterator) Entry() TimeEntry {
+	return it.entry
+}
+
+// Err returns the first error that occurred, nil if all entries were read successfully.
+func (it *EntryIterator) Err() error {
+	return it.err
+}
+
+// Close releases the response being read. It is safe to call Close multiple times and after Next returned false.
+func (it *EntryIterator) Close() error {
+	it.windows = nil
+	return it.closeBody()
+}
+
+// openWindow sends the request for a window and positions the decoder at the first entry.
+func (it *EntryIterator) openWindow(window DateRange) error {
+	params := it.params
+	params.From, params.To = window.From, window.To
+	queryUrl, err := timeEntryUrl(it.con, params)
+	if err != nil {
+		return err
+	}
+
+	body, err := open(it.ctx, it.con, http.MethodGet, queryUrl, nil)
+	if err != nil {
+		return err
+	}
+	it.body = body
+	it.decoder = json.NewDecoder(body)
+
+	token, err := it.decoder.Token()
+	if err != nil {
+		it.fail(err)
+		return it.err
+	}
+	if token == nil {
+		// null, treated like an empty array.
+		it.closeBody()
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		it.fail(fmt.Errorf("GetTimeEntries: expected JSON array, got %v", token))
+		return it.err
+	}
+	return nil
+}
+
+// fail records err and releases the response.
+func (it *EntryIterator) fail(err error) {
+	if it.err == nil {
+		it.err = redactError(it.con, err)
+	}
+	it.closeBody()
+}
+
+func (it *EntryIterator) closeBody() error {
+	it.decoder = nil
+	if it.body == nil {
+		return nil
+	}
+	err := it.body.Close()
+	it.body = nil
+	return err
+}
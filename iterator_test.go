@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryIterator(t *testing.T) {
+	const count = 1000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/from/2021-01-01/"):
+			_, _ = w.Write([]byte("["))
+			for i := 1; i <= count; i++ {
+				if i > 1 {
+					_, _ = w.Write([]byte(","))
+				}
+				_, _ = fmt.Fprintf(w, `{"id":%d,"duration":"60","task_id":"1","date":"2021-01-15"}`, i)
+			}
+			_, _ = w.Write([]byte("]"))
+		case strings.Contains(r.URL.Path, "/from/2021-02-01/"):
+			_, _ = w.Write([]byte(`[{"id":"5000","duration":"60"}]`))
+		case strings.Contains(r.URL.Path, "/from/2021-03-01/"):
+			_, _ = w.Write([]byte(`[{"id":6000,"duration":"60"},{"id":6001,"duration":"soon"}]`))
+		case strings.Contains(r.URL.Path, "/from/2021-04-01/"):
+			_, _ = w.Write([]byte(`{"error":"unexpected"}`))
+		default:
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+	month := func(m time.Month) DateRange {
+		return monthOf(time.Date(2021, m, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	t.Run("Windows", func(t *testing.T) {
+		params := DateRange{From: month(1).From, To: month(2).To}.Params()
+		params.Window = WindowMonth
+		it := IterateTimeEntries(context.Background(), c, params)
+		defer it.Close()
+
+		n := 0
+		var last ID
+		for it.Next() {
+			n++
+			last = it.Entry().ID
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("Err() = %v", err)
+		}
+		if n != count+1 || last != 5000 {
+			t.Errorf("iterated %d entries ending with %d, want %d ending with 5000", n, last, count+1)
+		}
+	})
+
+	t.Run("Decode Error", func(t *testing.T) {
+		it := IterateTimeEntries(context.Background(), c, month(3).Params())
+		defer it.Close()
+		n := 0
+		for it.Next() {
+			n++
+		}
+		var decodeErr *DecodeError
+		if n != 1 || !errors.As(it.Err(), &decodeErr) || decodeErr.ID != "6001" {
+			t.Errorf("iterated %d entries, Err() = %v, want DecodeError for entry 6001", n, it.Err())
+		}
+	})
+
+	t.Run("Not An Array", func(t *testing.T) {
+		if _, err := GetTimeEntries(c, month(4).Params()); err == nil {
+			t.Errorf("GetTimeEntries() expected error")
+		}
+	})
+
+	t.Run("Invalid Params", func(t *testing.T) {
+		it := IterateTimeEntries(context.Background(), c, DateRange{From: month(2).From, To: month(1).From}.Params())
+		if it.Next() || it.Err() == nil {
+			t.Errorf("Next() on invalid params should fail with error")
+		}
+	})
+
+	t.Run("Early Close", func(t *testing.T) {
+		it := IterateTimeEntries(context.Background(), c, month(1).Params())
+		if !it.Next() {
+			t.Fatalf("Next() = false, Err() = %v", it.Err())
+		}
+		if err := it.Close(); err != nil {
+			t.Errorf("Close() = %v", err)
+		}
+		if it.Next() {
+			t.Errorf("Next() after Close() = true")
+		}
+		if err := it.Close(); err != nil {
+			t.Errorf("second Close() = %v", err)
+		}
+	})
+}
+
+func TestGetTimeEntries_Empty(t *testing.T) {
+	for _, body := range []string{`[]`, `null`} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+		for _, window := range []Window{WindowNone, WindowMonth} {
+			params := DateRange{From: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC)}.Params()
+			params.Window = window
+			entries, err := GetTimeEntries(c, params)
+			if err != nil {
+				t.Fatalf("GetTimeEntries() error = %v", err)
+			}
+			// Callers marshalling the result expect [], not null.
+			if entries == nil || len(entries) != 0 {
+				t.Errorf("GetTimeEntries() with body %s and window %d = %#v, want empty slice", body, window, entries)
+			}
+		}
+		server.Close()
+	}
+}
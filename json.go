@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flexInt decodes integers TimeCamp sends either as JSON number or as string.
+// Empty strings and null decode to 0.
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	if len(data) == 0 {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.Atoi(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = flexInt(n)
+	return nil
+}
+
+// rawID returns an object's ID for error messages, without quotes.
+func rawID(data json.RawMessage) string {
+	return strings.Trim(string(bytes.TrimSpace(data)), `"`)
+}
+
+// fieldDecoder converts the raw values of an object's fields, remembering the first error as *DecodeError.
+type fieldDecoder struct {
+	object string
+	id     string
+	err    error
+}
+
+func (d *fieldDecoder) fail(field string, data json.RawMessage, err error) {
+	if d.err == nil {
+		d.err = &DecodeError{Object: d.object, ID: d.id, Field: field, Value: string(data), Err: err}
+	}
+}
+
+// text returns the value of a JSON string or number as string. Missing values and null are empty.
+func (d *fieldDecoder) text(field string, data json.RawMessage) (string, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return "", true
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			d.fail(field, data, err)
+			return "", false
+		}
+		return s, true
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		d.fail(field, data, fmt.Errorf("expected string or number"))
+		return "", false
+	}
+	return n.String(), true
+}
+
+func (d *fieldDecoder) string(field string, data json.RawMessage) string {
+	s, _ := d.text(field, data)
+	return s
+}
+
+func (d *fieldDecoder) int(field string, data json.RawMessage) int {
+	s, ok := d.text(field, data)
+	if !ok || s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		d.fail(field, data, fmt.Errorf("expected integer"))
+		return 0
+	}
+	return n
+}
+
+func (d *fieldDecoder) objectID(field string, data json.RawMessage) ID {
+	return ID(d.int(field, data))
+}
+
+// seconds decodes a duration given in seconds.
+func (d *fieldDecoder) seconds(field string, data json.RawMessage) time.Duration {
+	return time.Duration(d.int(field, data)) * time.Second
+}
+
+// bool accepts JSON booleans as well as 0 and 1, both as number and string.
+func (d *fieldDecoder) bool(field string, data json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("true")) || bytes.Equal(trimmed, []byte("false")) {
+		return trimmed[0] == 't'
+	}
+	s, ok := d.text(field, data)
+	if !ok {
+		return false
+	}
+	switch s {
+	case "", "0":
+		return false
+	case "1":
+		return true
+	}
+	d.fail(field, data, fmt.Errorf("expected boolean"))
+	return false
+}
+
+// time parses a timestamp with the given layout in UTC. Empty values and MySQL's zero dates are zero.
+func (d *fieldDecoder) time(field string, data json.RawMessage, layout string) time.Time {
+	s, ok := d.text(field, data)
+	if !ok || s == "" || strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		d.fail(field, data, err)
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_flexInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    flexInt
+		wantErr bool
+	}{
+		{name: "Number", json: `42`, want: 42},
+		{name: "String", json: `"42"`, want: 42},
+		{name: "Empty String", json: `""`, want: 0},
+		{name: "Null", json: `null`, want: 0},
+		{name: "Negative", json: `"-1"`, want: -1},
+		{name: "Float", json: `4.2`, wantErr: true},
+		{name: "Text", json: `"abc"`, wantErr: true},
+		{name: "Bool", json: `true`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got flexInt
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
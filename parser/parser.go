@@ -20,10 +20,10 @@ func (t Totals) add(total Totals) Totals {
 }
 
 // TaskTotals keeps totals for spent times for tasks
-type TaskTotals map[int]Totals
+type TaskTotals map[api.ID]Totals
 
 // add adds totals to a task total. Create map item if needed.
-func (t TaskTotals) add(taskId int, totals Totals) {
+func (t TaskTotals) add(taskId api.ID, totals Totals) {
 	currentTotals, ok := t[taskId]
 	if !ok {
 		t[taskId] = totals
@@ -33,7 +33,7 @@ func (t TaskTotals) add(taskId int, totals Totals) {
 }
 
 // Get returns the totals for task
-func (t TaskTotals) Get(taskId int) Totals {
+func (t TaskTotals) Get(taskId api.ID) Totals {
 	totals, ok := t[taskId]
 	if ok {
 		return totals
@@ -53,8 +53,8 @@ func GetProjectList(tasks []api.Task) []api.Task {
 	return result
 }
 
-// GetTaskById returns a task identified by its ID.
-func GetTaskById(tasks []api.Task, id int) (*api.Task, error) {
+// GetTaskById returns a task identified by its ID. Use TaskTree.Get for repeated lookups.
+func GetTaskById(tasks []api.Task, id api.ID) (*api.Task, error) {
 	for _, task := range tasks {
 		if task.TaskID == id {
 			return &task, nil
@@ -63,11 +63,11 @@ func GetTaskById(tasks []api.Task, id int) (*api.Task, error) {
 	return nil, fmt.Errorf("task with ID %d not found", id)
 }
 
-// GetEntriesForTask returns an array with time entries for the given task.
-func GetEntriesForTask(entries []api.TimeEntry, taskId int) []api.TimeEntry {
+// GetEntriesForTask returns an array with time entries for the given task. Use EntryIndex.Get for repeated lookups.
+func GetEntriesForTask(entries []api.TimeEntry, taskId api.ID) []api.TimeEntry {
 	var result []api.TimeEntry
 	for _, entry := range entries {
-		if entry.TaskIdInt() == taskId {
+		if entry.TaskID == taskId {
 			result = append(result, entry)
 		}
 	}
@@ -75,61 +75,27 @@ func GetEntriesForTask(entries []api.TimeEntry, taskId int) []api.TimeEntry {
 }
 
 // SummarizeTask summarizes the entries directly related to given task.
+// Durations are validated when decoding the entries, so err is always nil.
 func SummarizeTask(task api.Task, entries []api.TimeEntry) (billable time.Duration, total time.Duration, err error) {
-	taskEntries := GetEntriesForTask(entries, task.TaskID)
-	for _, entry := range taskEntries {
-		duration, err := entry.DurationParsed()
-		if err != nil {
-			return 0, 0, err
-		}
-		if entry.IsBillable() {
-			billable += duration
-		}
-		total += duration
-	}
-	return
+	totals := sumEntries(GetEntriesForTask(entries, task.TaskID))
+	return totals.BillableTime, totals.TotalTime, nil
 }
 
 // WalkTaskTree recursively walks down the task tree, starting at a given root task, calling a callback function for every node.
 // includeRoot controls if callback is also executed with root task.
-func WalkTaskTree(tasks []api.Task, root api.Task, includeRoot bool, callback func(api.Task, map[int]int)) {
+// The map passed to callback holds the IDs of the task's ancestors by level. It is reused, so copy it to keep it.
+func WalkTaskTree(tasks []api.Task, root api.Task, includeRoot bool, callback func(api.Task, map[int]api.ID)) {
 	traverseTree(tasks, root, includeRoot, callback)
 }
 
-// SummarizeTaskTree recursively walks down the task tree, starting at a given root task, summarizing all recorded times
+// SummarizeTaskTree recursively walks down the task tree, starting at a given root task, summarizing all recorded times.
+// To summarize several trees, build the indexes once and use TaskTree.Summarize.
 func SummarizeTaskTree(tasks []api.Task, entries []api.TimeEntry, root api.Task) TaskTotals {
-	var taskTotals = make(TaskTotals)
-	traverseTree(tasks, root, true, func(task api.Task, parentIds map[int]int) {
-		timeEntries := GetEntriesForTask(entries, task.TaskID)
-		var taskTimes Totals
-		for _, timeEntry := range timeEntries {
-			duration, _ := timeEntry.DurationParsed()
-			taskTimes.TotalTime += duration
-			if timeEntry.IsBillable() {
-				taskTimes.BillableTime += duration
-			}
-		}
-		taskTotals.add(task.TaskID, taskTimes)
-		for _, taskId := range parentIds {
-			taskTotals.add(taskId, taskTimes)
-		}
-	})
-	return taskTotals
+	return NewTaskTree(tasks).Summarize(root, NewEntryIndex(entries))
 }
 
-var parentIds = make(map[int]int)
-
-// traverseTree recursively walks down the task tree, starting at a given root task, calling a callback function for every node
-func traverseTree(tasks []api.Task, parent api.Task, includeParent bool, callback func(api.Task, map[int]int)) {
-	if includeParent && len(parentIds) == 0 {
-		callback(parent, parentIds)
-	}
-	for _, task := range tasks {
-		if task.ParentID == parent.TaskID {
-			parentIds[task.Level-1] = task.ParentID
-			callback(task, parentIds)
-			traverseTree(tasks, task, includeParent, callback)
-			delete(parentIds, task.Level-1)
-		}
-	}
+// traverseTree recursively walks down the task tree, starting at a given root task, calling a callback function for every node.
+// Each call has its own state, so it is safe for concurrent use.
+func traverseTree(tasks []api.Task, parent api.Task, includeParent bool, callback func(api.Task, map[int]api.ID)) {
+	NewTaskTree(tasks).Walk(parent, includeParent, callback)
 }
@@ -2,13 +2,73 @@ package parser
 
 import (
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	api "github.com/rupkoe/timecamp-api"
+	"github.com/rupkoe/timecamp-api/apitest"
 )
 
-// todo: use data from rupert's fixture file
+// fixture returns the tasks and time entries of the cassette testdata/synthetic.json.
+// The data is synthetic: it was recorded with apitest.Recorder from an apitest.Server, not from TimeCamp.
+// Replace it by a real recording to test with real-world payloads.
+// The tree has the projects 1001 (Website Relaunch), 2001 (Internal) and the archived 3001 (Old Shop).
+func fixture(t *testing.T) ([]api.Task, []api.TimeEntry) {
+	tasks, err := apitest.LoadTasks("testdata/synthetic.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := apitest.LoadTimeEntries("testdata/synthetic.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tasks, entries
+}
+
+func TestGetProjectList_Fixture(t *testing.T) {
+	tasks, _ := fixture(t)
+	var got []api.ID
+	for _, project := range GetProjectList(tasks) {
+		got = append(got, project.TaskID)
+	}
+	if want := []api.ID{1001, 2001, 3001}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetProjectList() = %v, want %v", got, want)
+	}
+}
+
+func TestWalkTaskTree_Fixture(t *testing.T) {
+	tasks, _ := fixture(t)
+	root, err := GetTaskById(tasks, 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []api.ID
+	WalkTaskTree(tasks, *root, true, func(task api.Task, parentIds map[int]api.ID) {
+		got = append(got, task.TaskID)
+	})
+	if want := []api.ID{1001, 1002, 1003, 1004, 1005}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkTaskTree() visited %v, want %v", got, want)
+	}
+}
+
+func TestSummarizeTaskTree_Fixture(t *testing.T) {
+	tasks, entries := fixture(t)
+	root, err := GetTaskById(tasks, 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TaskTotals{
+		1001: {TotalTime: 14 * time.Hour, BillableTime: 13*time.Hour + 15*time.Minute},
+		1002: {TotalTime: 5*time.Hour + 30*time.Minute, BillableTime: 4*time.Hour + 45*time.Minute},
+		1003: {TotalTime: 4*time.Hour + 45*time.Minute, BillableTime: 4*time.Hour + 45*time.Minute},
+		1004: {TotalTime: 45 * time.Minute},
+		1005: {TotalTime: 8 * time.Hour, BillableTime: 8 * time.Hour},
+	}
+	if got := SummarizeTaskTree(tasks, entries, *root); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeTaskTree() = %v, want %v", got, want)
+	}
+}
 
 func TestGetProjectList(t *testing.T) {
 	type args struct {
@@ -63,7 +123,7 @@ func TestTraverseTree(t *testing.T) {
 		tasks         []api.Task
 		parent        api.Task
 		includeParent bool
-		expectedIDs   []int
+		expectedIDs   []api.ID
 	}
 	tests := []struct {
 		name string
@@ -104,7 +164,7 @@ func TestTraverseTree(t *testing.T) {
 					Level:    1,
 				},
 				false,
-				[]int{12, 123},
+				[]api.ID{12, 123},
 			},
 		}, {
 			name: "Without parent task",
@@ -141,14 +201,14 @@ func TestTraverseTree(t *testing.T) {
 					Level:    1,
 				},
 				true,
-				[]int{1, 12, 123},
+				[]api.ID{1, 12, 123},
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			taskIds := map[int]struct{}{}
-			traverseTree(tt.args.tasks, tt.args.parent, tt.args.includeParent, func(task api.Task, m map[int]int) {
+			taskIds := map[api.ID]struct{}{}
+			traverseTree(tt.args.tasks, tt.args.parent, tt.args.includeParent, func(task api.Task, m map[int]api.ID) {
 				_, exists := taskIds[task.TaskID]
 				if !exists {
 					taskIds[task.TaskID] = struct{}{}
@@ -195,14 +255,14 @@ func TestSummarizeTaskTree(t *testing.T) {
 				[]api.TimeEntry{
 					{
 						ID:          1,
-						Duration:    "600",
-						TaskID:      "1",
+						Duration:    600 * time.Second,
+						TaskID:      1,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          2,
-						Duration:    "300",
-						TaskID:      "1",
+						Duration:    300 * time.Second,
+						TaskID:      1,
 						Billable:    0,
 						Description: "",
 					},
@@ -254,38 +314,38 @@ func TestSummarizeTaskTree(t *testing.T) {
 				[]api.TimeEntry{
 					{
 						ID:          1,
-						Duration:    "600",
-						TaskID:      "1",
+						Duration:    600 * time.Second,
+						TaskID:      1,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          2,
-						Duration:    "600",
-						TaskID:      "11",
+						Duration:    600 * time.Second,
+						TaskID:      11,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          2,
-						Duration:    "600",
-						TaskID:      "111",
+						Duration:    600 * time.Second,
+						TaskID:      111,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          2,
-						Duration:    "600",
-						TaskID:      "1111",
+						Duration:    600 * time.Second,
+						TaskID:      1111,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          2,
-						Duration:    "600",
-						TaskID:      "112",
+						Duration:    600 * time.Second,
+						TaskID:      112,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          3,
-						Duration:    "600",
-						TaskID:      "12",
+						Duration:    600 * time.Second,
+						TaskID:      12,
 						Billable:    1,
 						Description: "",
 					},
@@ -346,14 +406,14 @@ func TestSummarizeTask(t *testing.T) {
 				entries: []api.TimeEntry{
 					{
 						ID:          1,
-						Duration:    "1800",
-						TaskID:      "1",
+						Duration:    1800 * time.Second,
+						TaskID:      1,
 						Billable:    1,
 						Description: "",
 					}, {
 						ID:          2,
-						Duration:    "3600",
-						TaskID:      "1",
+						Duration:    3600 * time.Second,
+						TaskID:      1,
 						Billable:    0,
 						Description: "",
 					},
@@ -380,3 +440,53 @@ func TestSummarizeTask(t *testing.T) {
 		})
 	}
 }
+
+func TestSummarizeTaskTree_Concurrent(t *testing.T) {
+	tasks, entries := fixture(t)
+	projects := GetProjectList(tasks)
+	want := make([]TaskTotals, len(projects))
+	for i, project := range projects {
+		want[i] = SummarizeTaskTree(tasks, entries, project)
+	}
+
+	// Run with -race to detect shared traversal state.
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		for i, project := range projects {
+			wg.Add(1)
+			go func(i int, project api.Task) {
+				defer wg.Done()
+				if got := SummarizeTaskTree(tasks, entries, project); !reflect.DeepEqual(got, want[i]) {
+					t.Errorf("SummarizeTaskTree(%v) = %v, want %v", project.TaskID, got, want[i])
+				}
+			}(i, project)
+		}
+	}
+	wg.Wait()
+}
+
+func TestWalkTaskTree_PanickingCallback(t *testing.T) {
+	tasks, _ := fixture(t)
+	root, err := GetTaskById(tasks, 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		WalkTaskTree(tasks, *root, true, func(task api.Task, parentIds map[int]api.ID) {
+			if task.TaskID == 1003 {
+				panic("callback failed")
+			}
+		})
+	}()
+
+	// A failed walk must not leave state behind for the next one.
+	var got []api.ID
+	WalkTaskTree(tasks, *root, true, func(task api.Task, parentIds map[int]api.ID) {
+		got = append(got, task.TaskID)
+	})
+	if want := []api.ID{1001, 1002, 1003, 1004, 1005}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkTaskTree() after panic visited %v, want %v", got, want)
+	}
+}
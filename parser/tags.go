@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/rupkoe/timecamp-api"
+)
+
+// TagTotals keeps totals for spent times per tag name
+type TagTotals map[string]Totals
+
+// Get returns the totals for a tag, ignoring case
+func (t TagTotals) Get(tag string) Totals {
+	return t[normalizeTag(tag)]
+}
+
+// SummarizeTags summarizes the entries' times per tag. Entries with several tags count for each of them,
+// entries without tags are not counted. Request the entries with tags to use this.
+func SummarizeTags(entries []api.TimeEntry) TagTotals {
+	var tagTotals = make(TagTotals)
+	for _, entry := range entries {
+		var entryTimes = Totals{TotalTime: entry.Duration}
+		if entry.IsBillable() {
+			entryTimes.BillableTime = entry.Duration
+		}
+		seen := make(map[string]bool)
+		for _, tag := range entry.Tags {
+			name := normalizeTag(tag.Name)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			tagTotals[name] = tagTotals[name].add(entryTimes)
+		}
+	}
+	return tagTotals
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
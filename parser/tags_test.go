@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/rupkoe/timecamp-api"
+)
+
+func TestSummarizeTags(t *testing.T) {
+	meeting := api.Tag{ID: 1, Name: "Meeting"}
+	support := api.Tag{ID: 2, Name: "support"}
+	entries := []api.TimeEntry{
+		{ID: 1, Duration: 600 * time.Second, Billable: 1, Tags: []api.Tag{meeting}},
+		{ID: 2, Duration: 300 * time.Second, Billable: 0, Tags: []api.Tag{meeting, support}},
+		{ID: 3, Duration: 900 * time.Second, Billable: 1, Tags: []api.Tag{{ID: 3, Name: " SUPPORT "}}},
+		{ID: 4, Duration: 1200 * time.Second, Billable: 1},
+	}
+	want := TagTotals{
+		"meeting": {TotalTime: 900 * time.Second, BillableTime: 600 * time.Second},
+		"support": {TotalTime: 1200 * time.Second, BillableTime: 900 * time.Second},
+	}
+
+	got := SummarizeTags(entries)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeTags() = %v, want %v", got, want)
+	}
+	if got.Get("Meeting") != want["meeting"] {
+		t.Errorf("Get() = %v, want %v", got.Get("Meeting"), want["meeting"])
+	}
+}
@@ -0,0 +1,356 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/rupkoe/timecamp-api"
+)
+
+// TaskTree indexes tasks by ID and by parent. Build it once with NewTaskTree or BuildTaskTree to look up tasks in constant time.
+// Tasks keep the order of the slice they were built from. If several tasks have the same ID, the first one is used.
+type TaskTree struct {
+	tasks    []api.Task
+	byID     map[api.ID]int
+	children map[api.ID][]int
+}
+
+// OrphanRootID is the ID of the synthetic project orphaned tasks are attached to, see TreeOptions.
+const OrphanRootID api.ID = -1
+
+// TreeOptions controls how BuildTaskTree handles invalid trees.
+type TreeOptions struct {
+	// AttachOrphans makes tasks whose parent is missing subtasks of a synthetic project with OrphanRootID,
+	// so they show up when walking the tree from its roots. Their ParentID is changed in the tree's copy of the tasks.
+	AttachOrphans bool
+	// OrphanRootName is the name of the synthetic project, "Orphaned tasks" if empty.
+	OrphanRootName string
+}
+
+// Validation lists the problems found when building a TaskTree.
+type Validation struct {
+	// Orphans are tasks whose parent is not in the tree, e.g. because archived tasks were not fetched.
+	Orphans []api.Task
+	// Cycles holds the IDs of tasks whose parents form a loop, one slice per loop.
+	// These tasks cannot be reached from any project.
+	Cycles [][]api.ID
+	// LevelMismatches are tasks whose Level does not match their depth below their project.
+	// Only tasks reachable from a project are checked.
+	LevelMismatches []LevelMismatch
+	// Unreachable are all tasks that walking the tree from its projects never visits:
+	// orphans and cycle members as well as their subtasks.
+	Unreachable []api.Task
+}
+
+// LevelMismatch is a task whose Level differs from its Depth in the tree, with projects at depth 1.
+type LevelMismatch struct {
+	Task  api.Task
+	Depth int
+}
+
+// Valid reports whether no problems were found.
+func (v Validation) Valid() bool {
+	return len(v.Orphans) == 0 && len(v.Cycles) == 0 && len(v.LevelMismatches) == 0 && len(v.Unreachable) == 0
+}
+
+// Err returns an error summarizing the problems, nil if the tree is valid.
+func (v Validation) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return fmt.Errorf("invalid task tree: %d orphaned tasks, %d cycles, %d level mismatches, %d unreachable tasks",
+		len(v.Orphans), len(v.Cycles), len(v.LevelMismatches), len(v.Unreachable))
+}
+
+// NewTaskTree builds the tree of tasks. Use BuildTaskTree to check the tree for problems.
+func NewTaskTree(tasks []api.Task) *TaskTree {
+	tree, _ := BuildTaskTree(tasks, TreeOptions{})
+	return tree
+}
+
+// BuildTaskTree builds the tree of tasks and reports orphans, cycles, level mismatches and unreachable tasks.
+// The validation describes the tasks as given, before orphans are attached.
+func BuildTaskTree(tasks []api.Task, options TreeOptions) (*TaskTree, Validation) {
+	tree := &TaskTree{
+		tasks:    tasks,
+		byID:     make(map[api.ID]int, len(tasks)),
+		children: make(map[api.ID][]int),
+	}
+	for i, task := range tasks {
+		if _, exists := tree.byID[task.TaskID]; exists {
+			continue
+		}
+		tree.byID[task.TaskID] = i
+		tree.children[task.ParentID] = append(tree.children[task.ParentID], i)
+	}
+
+	orphans := tree.orphans()
+	levelMismatches, reachable := tree.reachable()
+	validation := Validation{
+		Cycles:          tree.cycles(),
+		LevelMismatches: levelMismatches,
+	}
+	for _, i := range orphans {
+		validation.Orphans = append(validation.Orphans, tree.tasks[i])
+	}
+	for _, i := range tree.indexes() {
+		if !reachable[tree.tasks[i].TaskID] {
+			validation.Unreachable = append(validation.Unreachable, tree.tasks[i])
+		}
+	}
+	if options.AttachOrphans && len(orphans) > 0 {
+		tree.attach(orphans, options.OrphanRootName)
+	}
+	return tree, validation
+}
+
+// indexes returns the indexes of the tasks in the tree, in order.
+func (t *TaskTree) indexes() []int {
+	var result []int
+	for i, task := range t.tasks {
+		if t.byID[task.TaskID] == i {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// orphans returns the indexes of tasks with a missing parent.
+func (t *TaskTree) orphans() []int {
+	var result []int
+	for _, i := range t.indexes() {
+		parentID := t.tasks[i].ParentID
+		if _, exists := t.byID[parentID]; parentID != 0 && !exists {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// cycles follows the parents of every task to find loops.
+func (t *TaskTree) cycles() [][]api.ID {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	var result [][]api.ID
+	state := make(map[api.ID]int)
+	for _, i := range t.indexes() {
+		var path []api.ID
+		for id := t.tasks[i].TaskID; id != 0; {
+			if state[id] == done {
+				break
+			}
+			if state[id] == visiting {
+				for k := range path {
+					if path[k] == id {
+						result = append(result, append([]api.ID(nil), path[k:]...))
+						break
+					}
+				}
+				break
+			}
+			index, exists := t.byID[id]
+			if !exists {
+				break
+			}
+			state[id] = visiting
+			path = append(path, id)
+			id = t.tasks[index].ParentID
+		}
+		for _, id := range path {
+			state[id] = done
+		}
+	}
+	return result
+}
+
+// reachable walks the tree from its projects. It returns the visited tasks
+// and compares their level with their depth.
+func (t *TaskTree) reachable() ([]LevelMismatch, map[api.ID]bool) {
+	var result []LevelMismatch
+	var check func(parent api.ID, depth int)
+	seen := make(map[api.ID]bool)
+	check = func(parent api.ID, depth int) {
+		for _, i := range t.children[parent] {
+			task := t.tasks[i]
+			if seen[task.TaskID] {
+				continue
+			}
+			seen[task.TaskID] = true
+			if task.Level != depth {
+				result = append(result, LevelMismatch{Task: task, Depth: depth})
+			}
+			check(task.TaskID, depth+1)
+		}
+	}
+	check(0, 1)
+	return result, seen
+}
+
+// attach makes the orphans subtasks of a synthetic project.
+func (t *TaskTree) attach(orphans []int, name string) {
+	if name == "" {
+		name = "Orphaned tasks"
+	}
+	// Don't modify the caller's tasks.
+	t.tasks = append([]api.Task(nil), t.tasks...)
+	t.tasks = append(t.tasks, api.Task{TaskID: OrphanRootID, Name: name, Level: 1})
+	root := len(t.tasks) - 1
+	t.byID[OrphanRootID] = root
+	t.children[0] = append(t.children[0], root)
+
+	for _, i := range orphans {
+		delete(t.children, t.tasks[i].ParentID)
+		t.tasks[i].ParentID = OrphanRootID
+		t.children[OrphanRootID] = append(t.children[OrphanRootID], i)
+	}
+}
+
+// Get returns the task with the given ID.
+func (t *TaskTree) Get(id api.ID) (api.Task, bool) {
+	i, ok := t.byID[id]
+	if !ok {
+		return api.Task{}, false
+	}
+	return t.tasks[i], true
+}
+
+// Children returns the direct subtasks of the task with the given ID.
+func (t *TaskTree) Children(id api.ID) []api.Task {
+	var result []api.Task
+	for _, i := range t.children[id] {
+		result = append(result, t.tasks[i])
+	}
+	return result
+}
+
+// Parent returns the parent of the task with the given ID. It returns false for projects and unknown tasks.
+func (t *TaskTree) Parent(id api.ID) (api.Task, bool) {
+	task, ok := t.Get(id)
+	if !ok || task.ParentID == 0 {
+		return api.Task{}, false
+	}
+	return t.Get(task.ParentID)
+}
+
+// Ancestors returns the parent, grandparent etc. of the task with the given ID, ending with its project.
+// It stops at a parent missing from the tree.
+func (t *TaskTree) Ancestors(id api.ID) []api.Task {
+	var result []api.Task
+	seen := map[api.ID]bool{id: true}
+	for {
+		parent, ok := t.Parent(id)
+		if !ok || seen[parent.TaskID] {
+			return result
+		}
+		seen[parent.TaskID] = true
+		result = append(result, parent)
+		id = parent.TaskID
+	}
+}
+
+// Descendants returns all subtasks of the task with the given ID, depth-first with each task before its subtasks.
+func (t *TaskTree) Descendants(id api.ID) []api.Task {
+	var result []api.Task
+	t.walk(id, map[api.ID]bool{id: true}, func(task api.Task) {
+		result = append(result, task)
+	})
+	return result
+}
+
+// Roots returns the projects, i.e. the top-level tasks.
+func (t *TaskTree) Roots() []api.Task {
+	return t.Children(0)
+}
+
+// Walk is like WalkTaskTree, but uses the tree's index.
+func (t *TaskTree) Walk(root api.Task, includeRoot bool, callback func(api.Task, map[int]api.ID)) {
+	parentIds := make(map[int]api.ID)
+	if includeRoot {
+		callback(root, parentIds)
+	}
+	t.walkParents(root.TaskID, parentIds, map[api.ID]bool{root.TaskID: true}, callback)
+}
+
+// walkParents visits the subtasks of parent, tracking the IDs of their parents by level in parentIds.
+func (t *TaskTree) walkParents(parent api.ID, parentIds map[int]api.ID, seen map[api.ID]bool, callback func(api.Task, map[int]api.ID)) {
+	for _, i := range t.children[parent] {
+		task := t.tasks[i]
+		if seen[task.TaskID] {
+			continue
+		}
+		seen[task.TaskID] = true
+		parentIds[task.Level-1] = task.ParentID
+		callback(task, parentIds)
+		t.walkParents(task.TaskID, parentIds, seen, callback)
+		delete(parentIds, task.Level-1)
+	}
+}
+
+// walk visits the subtasks of parent depth-first. seen guards against cycles.
+func (t *TaskTree) walk(parent api.ID, seen map[api.ID]bool, callback func(api.Task)) {
+	for _, i := range t.children[parent] {
+		task := t.tasks[i]
+		if seen[task.TaskID] {
+			continue
+		}
+		seen[task.TaskID] = true
+		callback(task)
+		t.walk(task.TaskID, seen, callback)
+	}
+}
+
+// Summarize is like SummarizeTaskTree, but uses the tree's and the entries' index.
+func (t *TaskTree) Summarize(root api.Task, entries EntryIndex) TaskTotals {
+	taskTotals := make(TaskTotals)
+	t.summarize(root.TaskID, entries, taskTotals, map[api.ID]bool{root.TaskID: true})
+	return taskTotals
+}
+
+// summarize adds the totals of a task including its subtasks to taskTotals and returns them.
+func (t *TaskTree) summarize(id api.ID, entries EntryIndex, taskTotals TaskTotals, seen map[api.ID]bool) Totals {
+	totals := entries.Totals(id)
+	for _, i := range t.children[id] {
+		child := t.tasks[i]
+		if seen[child.TaskID] {
+			continue
+		}
+		seen[child.TaskID] = true
+		totals = totals.add(t.summarize(child.TaskID, entries, taskTotals, seen))
+	}
+	taskTotals.add(id, totals)
+	return totals
+}
+
+// EntryIndex groups time entries by task. Build it once with NewEntryIndex to look up entries in constant time.
+type EntryIndex map[api.ID][]api.TimeEntry
+
+// NewEntryIndex groups the entries by task, keeping their order.
+func NewEntryIndex(entries []api.TimeEntry) EntryIndex {
+	index := make(EntryIndex)
+	for _, entry := range entries {
+		index[entry.TaskID] = append(index[entry.TaskID], entry)
+	}
+	return index
+}
+
+// Get returns the time entries of a task.
+func (i EntryIndex) Get(taskId api.ID) []api.TimeEntry {
+	return i[taskId]
+}
+
+// Totals summarizes the time entries directly related to a task.
+func (i EntryIndex) Totals(taskId api.ID) Totals {
+	return sumEntries(i[taskId])
+}
+
+func sumEntries(entries []api.TimeEntry) Totals {
+	var totals Totals
+	for _, entry := range entries {
+		totals.TotalTime += entry.Duration
+		if entry.IsBillable() {
+			totals.BillableTime += entry.Duration
+		}
+	}
+	return totals
+}
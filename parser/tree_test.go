@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/rupkoe/timecamp-api"
+)
+
+func ids(tasks []api.Task) []api.ID {
+	var result []api.ID
+	for _, task := range tasks {
+		result = append(result, task.TaskID)
+	}
+	return result
+}
+
+func TestTaskTree(t *testing.T) {
+	tasks, _ := fixture(t)
+	tree := NewTaskTree(tasks)
+
+	if task, ok := tree.Get(1003); !ok || task.Name != "Mockups" {
+		t.Errorf("Get(1003) = %v, %v", task, ok)
+	}
+	if _, ok := tree.Get(9); ok {
+		t.Errorf("Get(9) found a task")
+	}
+	if parent, ok := tree.Parent(1003); !ok || parent.TaskID != 1002 {
+		t.Errorf("Parent(1003) = %v, %v", parent, ok)
+	}
+	if _, ok := tree.Parent(1001); ok {
+		t.Errorf("Parent(1001) of project found a task")
+	}
+
+	tests := []struct {
+		name string
+		got  []api.Task
+		want []api.ID
+	}{
+		{"Roots", tree.Roots(), []api.ID{1001, 2001, 3001}},
+		{"Children", tree.Children(1001), []api.ID{1002, 1005}},
+		{"Children of leaf", tree.Children(1003), nil},
+		{"Ancestors", tree.Ancestors(1003), []api.ID{1002, 1001}},
+		{"Ancestors of project", tree.Ancestors(1001), nil},
+		{"Descendants", tree.Descendants(1001), []api.ID{1002, 1003, 1004, 1005}},
+		{"Descendants of unknown", tree.Descendants(9), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.got); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskTree_Cycle(t *testing.T) {
+	tree := NewTaskTree([]api.Task{
+		{TaskID: 1, ParentID: 3, Level: 1},
+		{TaskID: 2, ParentID: 1, Level: 2},
+		{TaskID: 3, ParentID: 2, Level: 3},
+	})
+
+	if got, want := ids(tree.Ancestors(1)), []api.ID{3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ancestors(1) = %v, want %v", got, want)
+	}
+	if got, want := ids(tree.Descendants(1)), []api.ID{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants(1) = %v, want %v", got, want)
+	}
+	var visited []api.ID
+	tree.Walk(api.Task{TaskID: 1}, true, func(task api.Task, parentIds map[int]api.ID) {
+		visited = append(visited, task.TaskID)
+	})
+	if want := []api.ID{1, 2, 3}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("Walk() visited %v, want %v", visited, want)
+	}
+}
+
+func TestTaskTree_Duplicates(t *testing.T) {
+	tree := NewTaskTree([]api.Task{
+		{TaskID: 1, Name: "first"},
+		{TaskID: 1, Name: "second"},
+	})
+	if task, _ := tree.Get(1); task.Name != "first" {
+		t.Errorf("Get(1) = %v, want the first task", task)
+	}
+	if roots := tree.Roots(); len(roots) != 1 {
+		t.Errorf("Roots() = %v, want one task", roots)
+	}
+}
+
+func TestTaskTree_Summarize(t *testing.T) {
+	tasks, entries := fixture(t)
+	tree := NewTaskTree(tasks)
+	index := NewEntryIndex(entries)
+
+	for _, project := range tree.Roots() {
+		if got, want := tree.Summarize(project, index), SummarizeTaskTree(tasks, entries, project); !reflect.DeepEqual(got, want) {
+			t.Errorf("Summarize(%v) = %v, want %v", project.TaskID, got, want)
+		}
+	}
+	want := TaskTotals{
+		2001: {TotalTime: 50 * time.Minute},
+		2002: {TotalTime: 30 * time.Minute},
+	}
+	if got := tree.Summarize(api.Task{TaskID: 2001}, index); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize(2001) = %v, want %v", got, want)
+	}
+}
+
+func TestEntryIndex(t *testing.T) {
+	_, entries := fixture(t)
+	index := NewEntryIndex(entries)
+
+	if got, want := index.Get(1003), GetEntriesForTask(entries, 1003); !reflect.DeepEqual(got, want) {
+		t.Errorf("Get(1003) = %v, want %v", got, want)
+	}
+	if got := index.Get(9); got != nil {
+		t.Errorf("Get(9) = %v, want nil", got)
+	}
+	want := Totals{TotalTime: 8 * time.Hour, BillableTime: 8 * time.Hour}
+	if got := index.Totals(1005); got != want {
+		t.Errorf("Totals(1005) = %v, want %v", got, want)
+	}
+}
+
+func TestBuildTaskTree_Valid(t *testing.T) {
+	tasks, _ := fixture(t)
+	if _, validation := BuildTaskTree(tasks, TreeOptions{}); !validation.Valid() || validation.Err() != nil {
+		t.Errorf("BuildTaskTree() validation = %+v, want valid", validation)
+	}
+}
+
+func TestBuildTaskTree_Validation(t *testing.T) {
+	tasks := []api.Task{
+		{TaskID: 1, ParentID: 0, Level: 1},
+		{TaskID: 2, ParentID: 1, Level: 3}, // level mismatch
+		{TaskID: 3, ParentID: 9, Level: 2}, // orphan
+		{TaskID: 4, ParentID: 3, Level: 3}, // subtask of orphan
+		{TaskID: 5, ParentID: 6, Level: 2}, // cycle
+		{TaskID: 6, ParentID: 5, Level: 2},
+		{TaskID: 7, ParentID: 7, Level: 1}, // self-reference
+		{TaskID: 8, ParentID: 5, Level: 3}, // subtask of cycle
+	}
+	_, validation := BuildTaskTree(tasks, TreeOptions{})
+
+	if got, want := ids(validation.Orphans), []api.ID{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+	if want := [][]api.ID{{5, 6}, {7}}; !reflect.DeepEqual(validation.Cycles, want) {
+		t.Errorf("Cycles = %v, want %v", validation.Cycles, want)
+	}
+	if want := []LevelMismatch{{Task: tasks[1], Depth: 2}}; !reflect.DeepEqual(validation.LevelMismatches, want) {
+		t.Errorf("LevelMismatches = %v, want %v", validation.LevelMismatches, want)
+	}
+	// Walking from project 1 misses orphans, cycles and everything below them.
+	if got, want := ids(validation.Unreachable), []api.ID{3, 4, 5, 6, 7, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unreachable = %v, want %v", got, want)
+	}
+	if validation.Valid() || validation.Err() == nil {
+		t.Errorf("Valid() = true, want false")
+	}
+}
+
+func TestBuildTaskTree_AttachOrphans(t *testing.T) {
+	tasks := []api.Task{
+		{TaskID: 1, ParentID: 0, Level: 1},
+		{TaskID: 3, ParentID: 9, Level: 2},
+		{TaskID: 4, ParentID: 3, Level: 3},
+		{TaskID: 5, ParentID: 8, Level: 2},
+	}
+	tree, validation := BuildTaskTree(tasks, TreeOptions{AttachOrphans: true})
+
+	if got, want := ids(validation.Orphans), []api.ID{3, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+	if got, want := ids(tree.Roots()), []api.ID{1, OrphanRootID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Roots() = %v, want %v", got, want)
+	}
+	if root, _ := tree.Get(OrphanRootID); root.Name != "Orphaned tasks" {
+		t.Errorf("Get(OrphanRootID) = %v", root)
+	}
+	if got, want := ids(tree.Descendants(OrphanRootID)), []api.ID{3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants(OrphanRootID) = %v, want %v", got, want)
+	}
+	if got, want := ids(tree.Ancestors(4)), []api.ID{3, OrphanRootID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ancestors(4) = %v, want %v", got, want)
+	}
+	if tasks[1].ParentID != 9 {
+		t.Errorf("BuildTaskTree() modified the given tasks")
+	}
+}
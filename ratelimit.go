@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles requests with a token bucket per API token.
+// It is safe for concurrent use and can be shared by several connections.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     rateLimit
+	overrides map[string]rateLimit
+	buckets   map[string]*bucket
+	now       func() time.Time
+}
+
+type rateLimit struct {
+	perSecond float64
+	burst     int
+}
+
+type bucket struct {
+	limit  rateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perSecond requests per API token on average,
+// with bursts of up to burst requests. A perSecond value of 0 or less disables limiting.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:     newRateLimit(perSecond, burst),
+		overrides: make(map[string]rateLimit),
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+	}
+}
+
+func newRateLimit(perSecond float64, burst int) rateLimit {
+	if burst < 1 {
+		burst = 1
+	}
+	return rateLimit{perSecond: perSecond, burst: burst}
+}
+
+// SetTokenLimit overrides the limit for requests made with the given API token.
+func (l *RateLimiter) SetTokenLimit(token string, perSecond float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit := newRateLimit(perSecond, burst)
+	l.overrides[token] = limit
+	if b, ok := l.buckets[token]; ok {
+		b.limit = limit
+		if b.tokens > float64(limit.burst) {
+			b.tokens = float64(limit.burst)
+		}
+	}
+}
+
+// Wait blocks until a request with the given API token is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, token string) error {
+	wait := l.reserve(token)
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel(token)
+		return err
+	}
+	return nil
+}
+
+// reserve takes a token from the bucket and returns how long to wait until it is actually available.
+func (l *RateLimiter) reserve(token string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[token]
+	if !ok {
+		limit, ok := l.overrides[token]
+		if !ok {
+			limit = l.limit
+		}
+		b = &bucket{limit: limit, tokens: float64(limit.burst), last: now}
+		l.buckets[token] = b
+	}
+	if b.limit.perSecond <= 0 {
+		return 0
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.perSecond
+	if b.tokens > float64(b.limit.burst) {
+		b.tokens = float64(b.limit.burst)
+	}
+	b.last = now
+
+	// Tokens may become negative: every waiting caller holds a reservation, so waiters are served in order.
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit.perSecond * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (l *RateLimiter) cancel(token string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[token]; ok && b.limit.perSecond > 0 {
+		b.tokens++
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_reserve(t *testing.T) {
+	now := time.Date(2021, 01, 01, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(2, 2)
+	l.now = func() time.Time { return now }
+	l.SetTokenLimit("SLOW", 1, 1)
+
+	steps := []struct {
+		name    string
+		token   string
+		advance time.Duration
+		want    time.Duration
+	}{
+		{name: "Burst 1", token: "TOKEN", want: 0},
+		{name: "Burst 2", token: "TOKEN", want: 0},
+		{name: "Throttled", token: "TOKEN", want: 500 * time.Millisecond},
+		{name: "Queued Behind Waiter", token: "TOKEN", want: time.Second},
+		{name: "Refilled", token: "TOKEN", advance: 2 * time.Second, want: 0},
+		{name: "Other Token Has Own Bucket", token: "OTHER", want: 0},
+		{name: "Override Burst", token: "SLOW", want: 0},
+		{name: "Override Rate", token: "SLOW", want: time.Second},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if got := l.reserve(step.token); got != step.want {
+			t.Errorf("%s: reserve() = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	l := NewRateLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if got := l.reserve("TOKEN"); got != 0 {
+			t.Fatalf("reserve() = %v, want 0", got)
+		}
+	}
+}
+
+func TestRateLimiter_WaitCancel(t *testing.T) {
+	l := NewRateLimiter(0.1, 1)
+	_ = l.Wait(context.Background(), "TOKEN")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "TOKEN"); err != context.DeadlineExceeded {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if tokens := l.buckets["TOKEN"].tokens; tokens < -0.01 {
+		t.Errorf("cancelled reservation was not returned, tokens = %v", tokens)
+	}
+}
+
+func TestRateLimiter_Concurrent(t *testing.T) {
+	l := NewRateLimiter(200, 5)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(context.Background(), "TOKEN"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 5 requests pass immediately, the other 20 need 100ms at 200 requests per second.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("25 requests took %v, expected them to be throttled", elapsed)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls if and how failed requests are retried.
+//
+// Network errors, 429 Too Many Requests and 5xx responses are retried.
+// A Retry-After header sent by TimeCamp takes precedence over the computed backoff.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles with every further retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the computed delay.
+	MaxBackoff time.Duration
+	// RetryAllMethods also retries non-GET requests, which might not be idempotent.
+	RetryAllMethods bool
+	// OnRetry is called before waiting for a retry, e.g. for logging. May be nil.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	// Attempt is the number of the failed attempt, starting at 1.
+	Attempt  int
+	Method   string
+	Endpoint string
+	Err      error
+	// Wait is the delay before the next attempt.
+	Wait time.Duration
+}
+
+// DefaultRetryPolicy returns a policy suitable for most batch jobs.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+	}
+}
+
+// attempts returns the number of attempts allowed for a request with the given method.
+func (p *RetryPolicy) attempts(method string) int {
+	if p == nil || p.MaxAttempts < 2 {
+		return 1
+	}
+	if method != http.MethodGet && !p.RetryAllMethods {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the delay after the given failed attempt (starting at 1).
+func (p *RetryPolicy) backoff(attempt int, err error) time.Duration {
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+
+	wait := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// Equal jitter: keep at least half of the delay, randomize the rest.
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// retryable is true if err might go away when repeating the request.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	// Everything else originates from the transport, e.g. connection resets.
+	return true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
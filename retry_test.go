@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "First Retry", attempt: 1, err: errors.New("reset"), min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "Third Retry", attempt: 3, err: errors.New("reset"), min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "Capped", attempt: 20, err: errors.New("reset"), min: 500 * time.Millisecond, max: time.Second},
+		{name: "Retry-After", attempt: 1, err: &Error{StatusCode: 429, RetryAfter: 5 * time.Second}, min: 5 * time.Second, max: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				if got := p.backoff(tt.attempt, tt.err); got < tt.min || got > tt.max {
+					t.Fatalf("backoff() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_attempts(t *testing.T) {
+	var nilPolicy *RetryPolicy
+	if got := nilPolicy.attempts(http.MethodGet); got != 1 {
+		t.Errorf("nil policy attempts() = %d, want 1", got)
+	}
+	p := &RetryPolicy{MaxAttempts: 3}
+	if got := p.attempts(http.MethodGet); got != 3 {
+		t.Errorf("GET attempts() = %d, want 3", got)
+	}
+	if got := p.attempts(http.MethodPost); got != 1 {
+		t.Errorf("POST attempts() = %d, want 1", got)
+	}
+	p.RetryAllMethods = true
+	if got := p.attempts(http.MethodPost); got != 3 {
+		t.Errorf("POST attempts() with RetryAllMethods = %d, want 3", got)
+	}
+}
+
+func TestGetTasks_Retry(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		status      int
+		wantCalls   int
+		wantRetries int
+		wantErr     bool
+	}{
+		{name: "Recovers From 5xx", failures: 2, status: http.StatusServiceUnavailable, wantCalls: 3, wantRetries: 2},
+		{name: "Recovers From 429", failures: 1, status: http.StatusTooManyRequests, wantCalls: 2, wantRetries: 1},
+		{name: "Gives Up", failures: 10, status: http.StatusBadGateway, wantCalls: 3, wantRetries: 2, wantErr: true},
+		{name: "No Retry On 4xx", failures: 1, status: http.StatusBadRequest, wantCalls: 1, wantRetries: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			var retries []RetryEvent
+			c := Connection{ApiUrl: server.URL, Token: "TOKEN", Retry: &RetryPolicy{
+				MaxAttempts: 3,
+				MinBackoff:  time.Millisecond,
+				MaxBackoff:  5 * time.Millisecond,
+				OnRetry:     func(e RetryEvent) { retries = append(retries, e) },
+			}}
+			_, err := GetTasks(c, TaskParams{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", calls, tt.wantCalls)
+			}
+			if len(retries) != tt.wantRetries {
+				t.Errorf("OnRetry called %d times, want %d", len(retries), tt.wantRetries)
+			}
+			for i, e := range retries {
+				if e.Attempt != i+1 || e.Err == nil {
+					t.Errorf("unexpected retry event %+v", e)
+				}
+			}
+		})
+	}
+}
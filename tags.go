@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TagList is a named group of tags.
+//
+// API Docs: https://github.com/timecamp/timecamp-api/blob/master/sections/tags.md
+type TagList struct {
+	ID       ID
+	Name     string
+	Archived bool
+	// Tags is only filled by GetTags.
+	Tags []Tag
+}
+
+// Tag classifies time entries, e.g. as "meeting" or "support".
+type Tag struct {
+	ID       ID
+	Name     string
+	ListID   ID
+	ListName string
+}
+
+// rawTag holds the differently named fields TimeCamp uses for tags in tag lists and on time entries.
+type rawTag struct {
+	ID          json.RawMessage `json:"id"`
+	TagID       json.RawMessage `json:"tagId"`
+	Name        json.RawMessage `json:"name"`
+	ListID      json.RawMessage `json:"list_id"`
+	TagListID   json.RawMessage `json:"tagListId"`
+	ListName    json.RawMessage `json:"list_name"`
+	TagListName json.RawMessage `json:"tagListName"`
+}
+
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	var raw rawTag
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.ID == nil {
+		raw.ID = raw.TagID
+	}
+	if raw.ListID == nil {
+		raw.ListID = raw.TagListID
+	}
+	if raw.ListName == nil {
+		raw.ListName = raw.TagListName
+	}
+
+	d := fieldDecoder{object: "tag", id: rawID(raw.ID)}
+	*t = Tag{
+		ID:       d.objectID("id", raw.ID),
+		Name:     d.string("name", raw.Name),
+		ListID:   d.objectID("list_id", raw.ListID),
+		ListName: d.string("list_name", raw.ListName),
+	}
+	return d.err
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID       ID     `json:"id"`
+		Name     string `json:"name"`
+		ListID   ID     `json:"list_id,omitempty"`
+		ListName string `json:"list_name,omitempty"`
+	}{t.ID, t.Name, t.ListID, t.ListName})
+}
+
+func (l *TagList) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID       json.RawMessage `json:"id"`
+		Name     json.RawMessage `json:"name"`
+		Archived json.RawMessage `json:"archived"`
+		Tags     json.RawMessage `json:"tags"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	d := fieldDecoder{object: "tag list", id: rawID(raw.ID)}
+	*l = TagList{
+		ID:       d.objectID("id", raw.ID),
+		Name:     d.string("name", raw.Name),
+		Archived: d.bool("archived", raw.Archived),
+		Tags:     d.tags("tags", raw.Tags),
+	}
+	for i := range l.Tags {
+		if l.Tags[i].ListID == 0 {
+			l.Tags[i].ListID = l.ID
+			l.Tags[i].ListName = l.Name
+		}
+	}
+	return d.err
+}
+
+func (l TagList) MarshalJSON() ([]byte, error) {
+	archived := "0"
+	if l.Archived {
+		archived = "1"
+	}
+	return json.Marshal(struct {
+		ID       ID     `json:"id"`
+		Name     string `json:"name"`
+		Archived string `json:"archived"`
+		Tags     []Tag  `json:"tags,omitempty"`
+	}{l.ID, l.Name, archived, l.Tags})
+}
+
+// tags decodes tags sent as array or, like most TimeCamp collections, as object keyed by ID.
+func (d *fieldDecoder) tags(field string, data json.RawMessage) []Tag {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var list []Tag
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list
+	}
+	var keyed map[string]Tag
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		d.fail(field, data, err)
+		return nil
+	}
+	for _, tag := range keyed {
+		list = append(list, tag)
+	}
+	// The order of a keyed object is lost, sort by ID to be deterministic.
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// joinIDs returns ids as comma separated list.
+func joinIDs(ids []ID) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// decodeTagLists decodes tag lists sent as array or as object keyed by ID.
+func decodeTagLists(data []byte) ([]TagList, error) {
+	var lists []TagList
+	if err := json.Unmarshal(data, &lists); err == nil {
+		return lists, nil
+	}
+	var keyed map[string]TagList
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return nil, err
+	}
+	for _, list := range keyed {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].ID < lists[j].ID })
+	return lists, nil
+}
+
+// GetTagLists wraps the "GET /tag_list" api endpoint. The lists' Tags are not filled.
+func GetTagLists(c Connection) ([]TagList, error) {
+	return GetTagListsContext(context.Background(), c)
+}
+
+// GetTagListsContext is like GetTagLists, but the request is bound to ctx.
+func GetTagListsContext(ctx context.Context, c Connection) ([]TagList, error) {
+	data, err := httpGet(ctx, c, endpointUrl(c, "tag_list"))
+	if err != nil {
+		return nil, err
+	}
+	return decodeTagLists(data)
+}
+
+// GetTags returns the tags of a tag list using the "GET /tag_list" api endpoint.
+func GetTags(c Connection, listID ID) ([]Tag, error) {
+	return GetTagsContext(context.Background(), c, listID)
+}
+
+// GetTagsContext is like GetTags, but the request is bound to ctx.
+func GetTagsContext(ctx context.Context, c Connection, listID ID) ([]Tag, error) {
+	if listID <= 0 {
+		return nil, fmt.Errorf("GetTags: invalid tag list ID %d", listID)
+	}
+
+	data, err := httpGet(ctx, c, endpointUrl(c, "tag_list")+"/list_id/"+listID.String()+"/tags/1")
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := decodeTagLists(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, list := range lists {
+		if list.ID == listID {
+			return list.Tags, nil
+		}
+	}
+	return nil, fmt.Errorf("GetTags: tag list with ID %d not found", listID)
+}
+
+// GetTimeEntryTags wraps the "GET /entries_tags" api endpoint, returning the tags keyed by time entry ID.
+func GetTimeEntryTags(c Connection, entryIDs ...ID) (map[ID][]Tag, error) {
+	return GetTimeEntryTagsContext(context.Background(), c, entryIDs...)
+}
+
+// GetTimeEntryTagsContext is like GetTimeEntryTags, but the request is bound to ctx.
+func GetTimeEntryTagsContext(ctx context.Context, c Connection, entryIDs ...ID) (map[ID][]Tag, error) {
+	if len(entryIDs) == 0 {
+		return nil, fmt.Errorf("GetTimeEntryTags: no time entry IDs given")
+	}
+
+	data, err := httpGet(ctx, c, endpointUrl(c, "entries_tags")+"/entry_ids/"+joinIDs(entryIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries without tags are sent as empty array, so the whole response may be an empty array.
+	var empty []json.RawMessage
+	if json.Unmarshal(data, &empty) == nil && len(empty) == 0 {
+		return map[ID][]Tag{}, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	result := make(map[ID][]Tag, len(raw))
+	for key, tags := range raw {
+		var entryID ID
+		if err := json.Unmarshal([]byte(`"`+key+`"`), &entryID); err != nil {
+			return nil, fmt.Errorf("GetTimeEntryTags: invalid time entry ID %q", key)
+		}
+		d := fieldDecoder{object: "time entry", id: key}
+		result[entryID] = d.tags("tags", tags)
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+	return result, nil
+}
+
+// AddTimeEntryTags wraps the "POST /entries_tags" api endpoint.
+func AddTimeEntryTags(c Connection, entryID ID, tagIDs ...ID) error {
+	return AddTimeEntryTagsContext(context.Background(), c, entryID, tagIDs...)
+}
+
+// AddTimeEntryTagsContext is like AddTimeEntryTags, but the request is bound to ctx.
+func AddTimeEntryTagsContext(ctx context.Context, c Connection, entryID ID, tagIDs ...ID) error {
+	form, err := entryTagsForm(entryID, tagIDs)
+	if err != nil {
+		return err
+	}
+	_, err = do(ctx, c, http.MethodPost, endpointUrl(c, "entries_tags"), form)
+	return err
+}
+
+// RemoveTimeEntryTags wraps the "DELETE /entries_tags" api endpoint.
+func RemoveTimeEntryTags(c Connection, entryID ID, tagIDs ...ID) error {
+	return RemoveTimeEntryTagsContext(context.Background(), c, entryID, tagIDs...)
+}
+
+// RemoveTimeEntryTagsContext is like RemoveTimeEntryTags, but the request is bound to ctx.
+func RemoveTimeEntryTagsContext(ctx context.Context, c Connection, entryID ID, tagIDs ...ID) error {
+	form, err := entryTagsForm(entryID, tagIDs)
+	if err != nil {
+		return err
+	}
+	_, err = do(ctx, c, http.MethodDelete, endpointUrl(c, "entries_tags"), form)
+	return err
+}
+
+func entryTagsForm(entryID ID, tagIDs []ID) (url.Values, error) {
+	if entryID <= 0 {
+		return nil, fmt.Errorf("time entry tags: invalid time entry ID %d", entryID)
+	}
+	if len(tagIDs) == 0 {
+		return nil, fmt.Errorf("time entry tags: no tag IDs given")
+	}
+	form := url.Values{}
+	form.Set("entry_id", entryID.String())
+	form.Set("tags", joinIDs(tagIDs))
+	return form, nil
+}
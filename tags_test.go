@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	var writes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/tag_list/") && strings.Contains(r.URL.Path, "/list_id/1/"):
+			_, _ = w.Write([]byte(`{"1":{"id":"1","name":"Activity","archived":"0",
+				"tags":{"6":{"id":"6","name":"support"},"5":{"id":"5","name":"meeting"}}}}`))
+		case strings.HasPrefix(r.URL.Path, "/tag_list/"):
+			_, _ = w.Write([]byte(`{"2":{"id":"2","name":"Old","archived":"1"},"1":{"id":"1","name":"Activity","archived":"0"}}`))
+		case strings.HasPrefix(r.URL.Path, "/entries_tags/") && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"101":[{"tagListName":"Activity","tagListId":"1","tagId":"5","name":"meeting"}],"102":[]}`))
+		case strings.HasPrefix(r.URL.Path, "/entries_tags/"):
+			body, _ := ioutil.ReadAll(r.Body)
+			form, _ := url.ParseQuery(string(body))
+			writes = append(writes, r.Method+" "+form.Get("entry_id")+" "+form.Get("tags"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	lists, err := GetTagLists(c)
+	if err != nil {
+		t.Fatalf("GetTagLists() error = %v", err)
+	}
+	wantLists := []TagList{{ID: 1, Name: "Activity"}, {ID: 2, Name: "Old", Archived: true}}
+	if !reflect.DeepEqual(lists, wantLists) {
+		t.Errorf("GetTagLists() got = %+v, want %+v", lists, wantLists)
+	}
+
+	tags, err := GetTags(c, 1)
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	wantTags := []Tag{
+		{ID: 5, Name: "meeting", ListID: 1, ListName: "Activity"},
+		{ID: 6, Name: "support", ListID: 1, ListName: "Activity"},
+	}
+	if !reflect.DeepEqual(tags, wantTags) {
+		t.Errorf("GetTags() got = %+v, want %+v", tags, wantTags)
+	}
+
+	entryTags, err := GetTimeEntryTags(c, 101, 102)
+	if err != nil {
+		t.Fatalf("GetTimeEntryTags() error = %v", err)
+	}
+	wantEntryTags := map[ID][]Tag{101: {wantTags[0]}, 102: {}}
+	if !reflect.DeepEqual(entryTags, wantEntryTags) {
+		t.Errorf("GetTimeEntryTags() got = %+v, want %+v", entryTags, wantEntryTags)
+	}
+
+	if err := AddTimeEntryTags(c, 101, 5, 6); err != nil {
+		t.Fatalf("AddTimeEntryTags() error = %v", err)
+	}
+	if err := RemoveTimeEntryTags(c, 101, 6); err != nil {
+		t.Fatalf("RemoveTimeEntryTags() error = %v", err)
+	}
+	if err := AddTimeEntryTags(c, 101); err == nil {
+		t.Errorf("AddTimeEntryTags() without tags expected error")
+	}
+	if want := []string{"POST 101 5,6", "DELETE 101 6"}; !reflect.DeepEqual(writes, want) {
+		t.Errorf("server received %v, want %v", writes, want)
+	}
+}
+
+func TestTimeEntry_Tags(t *testing.T) {
+	var entry TimeEntry
+	err := json.Unmarshal([]byte(`{"id":101,"tags":[{"tagListName":"Activity","tagListId":"1","tagId":"5","name":"Meeting"}]}`), &entry)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := []Tag{{ID: 5, Name: "Meeting", ListID: 1, ListName: "Activity"}}
+	if !reflect.DeepEqual(entry.Tags, want) {
+		t.Errorf("Tags = %+v, want %+v", entry.Tags, want)
+	}
+	if !entry.HasTag("meeting") || entry.HasTag("support") {
+		t.Errorf("HasTag() returned wrong results for %+v", entry.Tags)
+	}
+}
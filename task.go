@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 )
 
 // Task maps the JSON returned by TimeCamp API /tasks.
@@ -11,26 +15,26 @@ import (
 // API Docs: https://github.com/timecamp/timecamp-api/blob/master/sections/tasks.md
 // Created with https://mholt.github.io/json-to-go/
 type Task struct {
-	TaskID           int         `json:"task_id"`
-	ParentID         int         `json:"parent_id"`
-	AssignedBy       int         `json:"assigned_by"`
-	Name             string      `json:"name"`
-	ExternalTaskID   string      `json:"external_task_id"`
-	ExternalParentID string      `json:"external_parent_id"`
-	Level            int         `json:"level"`
-	Archived         int         `json:"archived"`
-	Tags             string      `json:"tags"`
-	Budgeted         int         `json:"budgeted"`
-	BudgetUnit       string      `json:"budget_unit"`
-	RootGroupID      int         `json:"root_group_id"`
-	Billable         int         `json:"billable"`
-	Note             string      `json:"note"`
-	PublicHash       string      `json:"public_hash"`
-	AddDate          string      `json:"add_date"`
-	ModifyTime       string      `json:"modify_time"`
-	Color            string      `json:"color"`
-	Users            interface{} `json:"users"`
-	UserAccessType   int         `json:"user_access_type"`
+	TaskID           ID        `json:"task_id"`
+	ParentID         ID        `json:"parent_id"`
+	AssignedBy       ID        `json:"assigned_by"`
+	Name             string    `json:"name"`
+	ExternalTaskID   string    `json:"external_task_id"`
+	ExternalParentID string    `json:"external_parent_id"`
+	Level            int       `json:"level"`
+	Archived         int       `json:"archived"`
+	Tags             string    `json:"tags"`
+	Budgeted         int       `json:"budgeted"`
+	BudgetUnit       string    `json:"budget_unit"`
+	RootGroupID      ID        `json:"root_group_id"`
+	Billable         int       `json:"billable"`
+	Note             string    `json:"note"`
+	PublicHash       string    `json:"public_hash"`
+	AddDate          string    `json:"add_date"`
+	ModifyTime       string    `json:"modify_time"`
+	Color            string    `json:"color"`
+	Users            TaskUsers `json:"users"`
+	UserAccessType   int       `json:"user_access_type"`
 }
 
 // IsProject is true if task is a project (=top-level task) in TimeCamp
@@ -39,20 +43,54 @@ func (t Task) IsProject() bool {
 	return t.ParentID == 0
 }
 
+// TagList returns the task's comma separated Tags trimmed, lower-cased and without duplicates.
+func (t Task) TagList() []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(t.Tags, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// HasTag is true if the task is tagged with tag, ignoring case and surrounding spaces.
+func (t Task) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, taskTag := range t.TagList() {
+		if taskTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
 type TaskParams struct {
 	OnlyArchivedTasks bool
 	OnlyActiveTasks   bool
+	// Order selects the order of sibling tasks, see SortTasks.
+	Order TaskOrder
 }
 
 // GetTasks wraps the "GET /tasks" api endpoint.
 // Both "Projects" and "Tasks" in TimeCamp's UI are tasks.
+// The tasks are returned in tree order, see SortTasks.
 func GetTasks(c Connection, params TaskParams) ([]Task, error) {
+	return GetTasksContext(context.Background(), c, params)
+}
+
+// GetTasksContext is like GetTasks, but the request is bound to ctx.
+func GetTasksContext(ctx context.Context, c Connection, params TaskParams) ([]Task, error) {
 	queryUrl, err := taskUrl(c, params)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := httpGet(queryUrl)
+	data, err := httpGet(ctx, c, queryUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +107,8 @@ func GetTasks(c Connection, params TaskParams) ([]Task, error) {
 	for _, t := range result {
 		tasks = append(tasks, t)
 	}
+	// Map order is random, make the result deterministic.
+	SortTasks(tasks, params.Order)
 	return tasks, nil
 }
 
@@ -84,9 +124,92 @@ func taskUrl(connection Connection, params TaskParams) (string, error) {
 		exclude = "" //nothing excluded
 	}
 
-	queryUrl, err := url.Parse(connection.ApiUrl + "/tasks/format/json/api_token/" + connection.Token + "?" + exclude)
+	queryUrl, err := url.Parse(endpointUrl(connection, "tasks") + "?" + exclude)
 	if err != nil {
-		return "", err
+		return "", redactError(connection, err)
 	}
 	return queryUrl.String(), err
 }
+
+// taskForm converts the writable fields of task to the form values expected by TimeCamp.
+func taskForm(task Task) url.Values {
+	form := url.Values{}
+	form.Set("name", task.Name)
+	form.Set("parent_id", task.ParentID.String())
+	form.Set("billable", strconv.Itoa(task.Billable))
+	form.Set("budgeted", strconv.Itoa(task.Budgeted))
+	form.Set("budget_unit", task.BudgetUnit)
+	form.Set("tags", task.Tags)
+	form.Set("note", task.Note)
+	form.Set("color", task.Color)
+	return form
+}
+
+// CreateTask wraps the "POST /tasks" api endpoint and returns the new task.
+// Name, ParentID, Billable, Budgeted, BudgetUnit, Tags, Note and Color of task are used, other fields are ignored.
+func CreateTask(c Connection, task Task) (Task, error) {
+	return CreateTaskContext(context.Background(), c, task)
+}
+
+// CreateTaskContext is like CreateTask, but the request is bound to ctx.
+func CreateTaskContext(ctx context.Context, c Connection, task Task) (Task, error) {
+	if task.Name == "" {
+		return Task{}, fmt.Errorf("CreateTask: task name must not be empty")
+	}
+
+	data, err := do(ctx, c, http.MethodPost, endpointUrl(c, "tasks"), taskForm(task))
+	if err != nil {
+		return Task{}, err
+	}
+
+	// Like GET /tasks, the created task is returned keyed by its ID.
+	var result map[string]Task
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return Task{}, err
+	}
+	for _, t := range result {
+		return t, nil
+	}
+	return Task{}, fmt.Errorf("CreateTask: no task returned")
+}
+
+// UpdateTask wraps the "PUT /tasks" api endpoint.
+// The same fields as in CreateTask are sent, so task should contain the complete new state, e.g. as retrieved by GetTasks.
+// Changing ParentID moves the task (and its subtasks) to another parent; 0 makes it a project.
+func UpdateTask(c Connection, task Task) error {
+	return UpdateTaskContext(context.Background(), c, task)
+}
+
+// UpdateTaskContext is like UpdateTask, but the request is bound to ctx.
+func UpdateTaskContext(ctx context.Context, c Connection, task Task) error {
+	if task.TaskID <= 0 {
+		return fmt.Errorf("UpdateTask: invalid task ID %d", task.TaskID)
+	}
+	if task.Name == "" {
+		return fmt.Errorf("UpdateTask: task name must not be empty")
+	}
+	form := taskForm(task)
+	form.Set("task_id", task.TaskID.String())
+
+	_, err := do(ctx, c, http.MethodPut, endpointUrl(c, "tasks"), form)
+	return err
+}
+
+// ArchiveTask archives a task using the "PUT /tasks" api endpoint.
+func ArchiveTask(c Connection, taskID ID) error {
+	return ArchiveTaskContext(context.Background(), c, taskID)
+}
+
+// ArchiveTaskContext is like ArchiveTask, but the request is bound to ctx.
+func ArchiveTaskContext(ctx context.Context, c Connection, taskID ID) error {
+	if taskID <= 0 {
+		return fmt.Errorf("ArchiveTask: invalid task ID %d", taskID)
+	}
+	form := url.Values{}
+	form.Set("task_id", taskID.String())
+	form.Set("archived", "1")
+
+	_, err := do(ctx, c, http.MethodPut, endpointUrl(c, "tasks"), form)
+	return err
+}
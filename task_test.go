@@ -1,6 +1,13 @@
 package api
 
-import "testing"
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
 
 func Test_taskUrl(t *testing.T) {
 	type args struct {
@@ -68,6 +75,14 @@ func Test_taskUrl(t *testing.T) {
 			},
 			want:    "apiurl//%7Bquery%7D%5B#]/tasks/format/json/api_token/TOKEN?",
 			wantErr: false,
+		}, {
+			name: "Token In Header",
+			args: args{
+				c: Connection{ApiUrl: "http://apiurl", Token: "TOKEN", Auth: AuthHeader},
+				p: TaskParams{},
+			},
+			want:    "http://apiurl/tasks/format/json?",
+			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
@@ -83,3 +98,82 @@ func Test_taskUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskWrites(t *testing.T) {
+	type received struct {
+		method string
+		form   url.Values
+	}
+	var got []received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		got = append(got, received{r.Method, form})
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"42":{"task_id":42,"parent_id":1,"name":"Task B","billable":1}}`))
+		}
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	task, err := CreateTask(c, Task{Name: "Task B", ParentID: 1, Billable: 1, Tags: "a,b"})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if task.TaskID != 42 || task.Name != "Task B" {
+		t.Errorf("CreateTask() got = %+v", task)
+	}
+	task.ParentID = 0
+	if err := UpdateTask(c, task); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if err := ArchiveTask(c, task.TaskID); err != nil {
+		t.Fatalf("ArchiveTask() error = %v", err)
+	}
+	if _, err := CreateTask(c, Task{}); err == nil {
+		t.Errorf("CreateTask() without name expected error")
+	}
+	if err := UpdateTask(c, Task{Name: "No ID"}); err == nil {
+		t.Errorf("UpdateTask() without ID expected error")
+	}
+
+	fields := func(parentID, tags string) url.Values {
+		return url.Values{"name": {"Task B"}, "parent_id": {parentID}, "billable": {"1"}, "budgeted": {"0"},
+			"budget_unit": {""}, "tags": {tags}, "note": {""}, "color": {""}}
+	}
+	update := fields("0", "")
+	update.Set("task_id", "42")
+	want := []received{
+		{http.MethodPost, fields("1", "a,b")},
+		{http.MethodPut, update},
+		{http.MethodPut, url.Values{"task_id": {"42"}, "archived": {"1"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("server received %v, want %v", got, want)
+	}
+}
+
+func TestTask_TagList(t *testing.T) {
+	tests := []struct {
+		name string
+		tags string
+		want []string
+	}{
+		{name: "Empty", tags: "", want: nil},
+		{name: "Single", tags: "Support", want: []string{"support"}},
+		{name: "Normalized", tags: " Meeting, support,,MEETING , Internal ", want: []string{"meeting", "support", "internal"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := Task{Tags: tt.tags}
+			if got := task.TagList(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TagList() = %v, want %v", got, tt.want)
+			}
+			for _, tag := range tt.want {
+				if !task.HasTag(" " + tag) {
+					t.Errorf("HasTag(%q) = false", tag)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+// TaskOrder selects how sibling tasks are ordered.
+type TaskOrder int
+
+const (
+	// OrderByID orders siblings by TaskID.
+	OrderByID TaskOrder = iota
+	// OrderByName orders siblings by name, ignoring case, then by TaskID.
+	OrderByName
+)
+
+func (o TaskOrder) less(a, b Task) bool {
+	if o == OrderByName {
+		if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+			return an < bn
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+	}
+	return a.TaskID < b.TaskID
+}
+
+// SortTasks sorts tasks in tree order: every task is followed by its subtasks (depth-first),
+// siblings are ordered by order. Tasks whose parent is not in tasks are sorted like projects.
+func SortTasks(tasks []Task, order TaskOrder) {
+	present := make(map[ID]bool, len(tasks))
+	for _, task := range tasks {
+		present[task.TaskID] = true
+	}
+
+	// Work on indexes, so duplicate task IDs don't get lost.
+	var roots []int
+	children := make(map[ID][]int)
+	for i, task := range tasks {
+		if task.ParentID == 0 || task.ParentID == task.TaskID || !present[task.ParentID] {
+			roots = append(roots, i)
+		} else {
+			children[task.ParentID] = append(children[task.ParentID], i)
+		}
+	}
+	sortSiblings := func(siblings []int) {
+		sort.SliceStable(siblings, func(i, j int) bool { return order.less(tasks[siblings[i]], tasks[siblings[j]]) })
+	}
+
+	sorted := make([]Task, 0, len(tasks))
+	added := make([]bool, len(tasks))
+	expanded := make(map[ID]bool, len(tasks))
+	var walk func(siblings []int)
+	walk = func(siblings []int) {
+		sortSiblings(siblings)
+		for _, i := range siblings {
+			if added[i] {
+				continue
+			}
+			added[i] = true
+			sorted = append(sorted, tasks[i])
+			if id := tasks[i].TaskID; !expanded[id] {
+				expanded[id] = true
+				walk(children[id])
+			}
+		}
+	}
+	walk(roots)
+
+	// Tasks in a parent cycle are not reachable from any root; keep them at the end instead of dropping them.
+	var rest []int
+	for i := range tasks {
+		if !added[i] {
+			rest = append(rest, i)
+		}
+	}
+	sortSiblings(rest)
+	for _, i := range rest {
+		sorted = append(sorted, tasks[i])
+	}
+	copy(tasks, sorted)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSortTasks(t *testing.T) {
+	tasks := []Task{
+		{TaskID: 1, ParentID: 0, Name: "Zulu"},
+		{TaskID: 2, ParentID: 0, Name: "alpha"},
+		{TaskID: 11, ParentID: 1, Name: "Beta"},
+		{TaskID: 12, ParentID: 1, Name: "alpha"},
+		{TaskID: 111, ParentID: 11, Name: "Gamma"},
+		{TaskID: 21, ParentID: 2, Name: "Delta"},
+		{TaskID: 31, ParentID: 3, Name: "Orphan"},
+		{TaskID: 41, ParentID: 42, Name: "Cycle A"},
+		{TaskID: 42, ParentID: 41, Name: "Cycle B"},
+	}
+	tests := []struct {
+		name  string
+		order TaskOrder
+		want  []ID
+	}{
+		{name: "By ID", order: OrderByID, want: []ID{1, 11, 111, 12, 2, 21, 31, 41, 42}},
+		{name: "By Name", order: OrderByName, want: []ID{2, 21, 31, 1, 12, 11, 111, 41, 42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				shuffled := append([]Task(nil), tasks...)
+				rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+				SortTasks(shuffled, tt.order)
+
+				var got []ID
+				for _, task := range shuffled {
+					got = append(got, task.TaskID)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("SortTasks() order = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSortTasks_Duplicates(t *testing.T) {
+	tasks := []Task{{TaskID: 2}, {TaskID: 1, Name: "first"}, {TaskID: 1, Name: "second"}}
+	SortTasks(tasks, OrderByID)
+	want := []Task{{TaskID: 1, Name: "first"}, {TaskID: 1, Name: "second"}, {TaskID: 2}}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("SortTasks() = %v, want %v", tasks, want)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// TaskUserAssignment is a user's assignment to a task.
+type TaskUserAssignment struct {
+	UserID ID
+	Role   Role
+	// AccessType is 0 if TimeCamp did not report it per user, see Task.UserAccessType.
+	AccessType int
+}
+
+// TaskUsers holds the users assigned to a task, keyed by user ID.
+type TaskUsers map[ID]TaskUserAssignment
+
+type rawTaskUserAssignment struct {
+	UserID         ID      `json:"user_id"`
+	RoleID         flexInt `json:"role_id"`
+	AccessType     flexInt `json:"access_type"`
+	UserAccessType flexInt `json:"user_access_type"`
+}
+
+func (a rawTaskUserAssignment) assignment(id ID) TaskUserAssignment {
+	accessType := a.AccessType
+	if accessType == 0 {
+		accessType = a.UserAccessType
+	}
+	return TaskUserAssignment{UserID: id, Role: Role(a.RoleID), AccessType: int(accessType)}
+}
+
+// UnmarshalJSON handles the shapes TimeCamp uses for task users:
+// an object keyed by user ID, an array of assignments and an empty array if nobody is assigned.
+func (u *TaskUsers) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var list []rawTaskUserAssignment
+	if err := json.Unmarshal(data, &list); err == nil {
+		users := make(TaskUsers, len(list))
+		for _, raw := range list {
+			if raw.UserID <= 0 {
+				return fmt.Errorf("task users: assignment without user ID")
+			}
+			users[raw.UserID] = raw.assignment(raw.UserID)
+		}
+		*u = users
+		return nil
+	}
+
+	var keyed map[string]rawTaskUserAssignment
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return fmt.Errorf("task users: unexpected JSON %.40s", data)
+	}
+	users := make(TaskUsers, len(keyed))
+	for key, raw := range keyed {
+		id := raw.UserID
+		if id == 0 {
+			n, err := strconv.Atoi(key)
+			if err != nil {
+				return fmt.Errorf("task users: invalid user ID %q", key)
+			}
+			id = ID(n)
+		}
+		users[id] = raw.assignment(id)
+	}
+	*u = users
+	return nil
+}
+
+// AssignedUserIDs returns the IDs of all users assigned to the task in ascending order.
+func (t Task) AssignedUserIDs() []ID {
+	ids := make([]ID, 0, len(t.Users))
+	for id := range t.Users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// IsAssignedTo is true if the user is assigned to the task.
+func (t Task) IsAssignedTo(userID ID) bool {
+	_, ok := t.Users[userID]
+	return ok
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTaskUsers_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    TaskUsers
+		wantErr bool
+	}{
+		{name: "Unassigned", json: `[]`, want: TaskUsers{}},
+		{name: "Null", json: `null`, want: nil},
+		{
+			name: "Keyed Object",
+			json: `{"7":{"user_id":"7","role_id":"3"},"8":{"role_id":5,"access_type":"2"}}`,
+			want: TaskUsers{7: {UserID: 7, Role: RoleUser}, 8: {UserID: 8, Role: RoleGuest, AccessType: 2}},
+		}, {
+			name: "Array",
+			json: `[{"user_id":7,"role_id":"3","user_access_type":"1"}]`,
+			want: TaskUsers{7: {UserID: 7, Role: RoleUser, AccessType: 1}},
+		},
+		{name: "Array Without User ID", json: `[{"role_id":3}]`, wantErr: true},
+		{name: "Invalid Key", json: `{"x":{}}`, wantErr: true},
+		{name: "Unexpected Type", json: `"7"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TaskUsers
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_Assignments(t *testing.T) {
+	var task Task
+	err := json.Unmarshal([]byte(`{"task_id":1,"users":{"9":{"role_id":"3"},"7":{"role_id":"1"}}}`), &task)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := task.AssignedUserIDs(); !reflect.DeepEqual(got, []ID{7, 9}) {
+		t.Errorf("AssignedUserIDs() = %v, want [7 9]", got)
+	}
+	if !task.IsAssignedTo(7) || task.IsAssignedTo(8) {
+		t.Errorf("IsAssignedTo() returned wrong results for %v", task.Users)
+	}
+
+	var unassigned Task
+	if err := json.Unmarshal([]byte(`{"task_id":2,"users":[]}`), &unassigned); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := unassigned.AssignedUserIDs(); len(got) != 0 {
+		t.Errorf("AssignedUserIDs() = %v, want none", got)
+	}
+}
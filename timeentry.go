@@ -1,9 +1,11 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -12,47 +14,212 @@ import (
 
 // TimeEntry maps the JSON  returned by TimeCamp API /entries.
 //
+// TimeCamp encodes most values as strings; they are converted to proper types when decoding.
+// Dates and times are the wall clock of the account's time zone, expressed as UTC.
+//
 // API docs: https://github.com/timecamp/timecamp-api/blob/master/sections/time-entries.md
-// Created with https://mholt.github.io/json-to-go/
 type TimeEntry struct {
-	ID               int    `json:"id"`
-	Duration         string `json:"duration"`
-	UserID           string `json:"user_id"`
-	UserName         string `json:"user_name"`
-	TaskID           string `json:"task_id"`
-	LastModify       string `json:"last_modify"`
-	Date             string `json:"date"`
-	StartTime        string `json:"start_time"`
-	EndTime          string `json:"end_time"`
-	Locked           string `json:"locked"`
-	Name             string `json:"name"`
-	AddonsExternalID string `json:"addons_external_id"`
-	Billable         int    `json:"billable"`
-	InvoiceID        string `json:"invoiceId"`
-	Color            string `json:"color"`
-	Description      string `json:"description"`
+	ID       ID
+	Duration time.Duration
+	UserID   ID
+	UserName string
+	TaskID   ID
+	// LastModify is zero if TimeCamp did not report it.
+	LastModify time.Time
+	// Date is the day of the entry at midnight.
+	Date time.Time
+	// StartTime and EndTime only hold the time of day (on January 1st of year 0). They are zero if not recorded.
+	StartTime        time.Time
+	EndTime          time.Time
+	Locked           bool
+	Name             string
+	AddonsExternalID string
+	Billable         int
+	InvoiceID        string
+	Color            string
+	Description      string
+	// Tags is only filled if requested from TimeCamp.
+	Tags []Tag
+}
+
+// rawTimeEntry holds the undecoded values of a time entry's JSON.
+type rawTimeEntry struct {
+	ID               json.RawMessage `json:"id"`
+	Duration         json.RawMessage `json:"duration"`
+	UserID           json.RawMessage `json:"user_id"`
+	UserName         json.RawMessage `json:"user_name"`
+	TaskID           json.RawMessage `json:"task_id"`
+	LastModify       json.RawMessage `json:"last_modify"`
+	Date             json.RawMessage `json:"date"`
+	StartTime        json.RawMessage `json:"start_time"`
+	EndTime          json.RawMessage `json:"end_time"`
+	Locked           json.RawMessage `json:"locked"`
+	Name             json.RawMessage `json:"name"`
+	AddonsExternalID json.RawMessage `json:"addons_external_id"`
+	Billable         json.RawMessage `json:"billable"`
+	InvoiceID        json.RawMessage `json:"invoiceId"`
+	Color            json.RawMessage `json:"color"`
+	Description      json.RawMessage `json:"description"`
+	Tags             json.RawMessage `json:"tags"`
 }
 
+// UnmarshalJSON decodes a time entry, accepting numbers and strings for numeric values.
+// Invalid values are reported as *DecodeError.
+func (e *TimeEntry) UnmarshalJSON(data []byte) error {
+	var raw rawTimeEntry
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	d := fieldDecoder{object: "time entry", id: rawID(raw.ID)}
+	*e = TimeEntry{
+		ID:               d.objectID("id", raw.ID),
+		Duration:         d.seconds("duration", raw.Duration),
+		UserID:           d.objectID("user_id", raw.UserID),
+		UserName:         d.string("user_name", raw.UserName),
+		TaskID:           d.objectID("task_id", raw.TaskID),
+		LastModify:       d.time("last_modify", raw.LastModify, DateTimeFormat),
+		Date:             d.time("date", raw.Date, DateFormat),
+		StartTime:        d.time("start_time", raw.StartTime, TimeFormat),
+		EndTime:          d.time("end_time", raw.EndTime, TimeFormat),
+		Locked:           d.bool("locked", raw.Locked),
+		Name:             d.string("name", raw.Name),
+		AddonsExternalID: d.string("addons_external_id", raw.AddonsExternalID),
+		Billable:         d.int("billable", raw.Billable),
+		InvoiceID:        d.string("invoiceId", raw.InvoiceID),
+		Color:            d.string("color", raw.Color),
+		Description:      d.string("description", raw.Description),
+		Tags:             d.tags("tags", raw.Tags),
+	}
+	return d.err
+}
+
+// MarshalJSON encodes a time entry the way TimeCamp does.
+func (e TimeEntry) MarshalJSON() ([]byte, error) {
+	formatTime := func(t time.Time, layout string) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	}
+	locked := "0"
+	if e.Locked {
+		locked = "1"
+	}
+	return json.Marshal(struct {
+		ID               ID     `json:"id"`
+		Duration         string `json:"duration"`
+		UserID           string `json:"user_id"`
+		UserName         string `json:"user_name"`
+		TaskID           string `json:"task_id"`
+		LastModify       string `json:"last_modify"`
+		Date             string `json:"date"`
+		StartTime        string `json:"start_time"`
+		EndTime          string `json:"end_time"`
+		Locked           string `json:"locked"`
+		Name             string `json:"name"`
+		AddonsExternalID string `json:"addons_external_id"`
+		Billable         int    `json:"billable"`
+		InvoiceID        string `json:"invoiceId"`
+		Color            string `json:"color"`
+		Description      string `json:"description"`
+		Tags             []Tag  `json:"tags,omitempty"`
+	}{
+		ID:               e.ID,
+		Duration:         strconv.FormatInt(int64(e.Duration/time.Second), 10),
+		UserID:           e.UserID.String(),
+		UserName:         e.UserName,
+		TaskID:           e.TaskID.String(),
+		LastModify:       formatTime(e.LastModify, DateTimeFormat),
+		Date:             formatTime(e.Date, DateFormat),
+		StartTime:        formatTime(e.StartTime, TimeFormat),
+		EndTime:          formatTime(e.EndTime, TimeFormat),
+		Locked:           locked,
+		Name:             e.Name,
+		AddonsExternalID: e.AddonsExternalID,
+		Billable:         e.Billable,
+		InvoiceID:        e.InvoiceID,
+		Color:            e.Color,
+		Description:      e.Description,
+		Tags:             e.Tags,
+	})
+}
+
+// Deprecated: use TaskID.
 func (t TimeEntry) TaskIdInt() int {
-	id, _ := strconv.Atoi(t.TaskID)
-	return id
+	return int(t.TaskID)
 }
 
+// Deprecated: use Duration.
 func (e TimeEntry) DurationParsed() (time.Duration, error) {
-	secs := strings.Join([]string{e.Duration, "s"}, "")
-	dur, err := time.ParseDuration(secs)
+	return e.Duration, nil
+}
+
+// Deprecated: use Date or DateIn.
+func (e TimeEntry) DateParsed() time.Time {
+	return e.Date
+}
+
+// ErrNotRecorded is returned by the TimeEntry time accessors if TimeCamp did not report the requested value.
+var ErrNotRecorded = errors.New("not recorded")
+
+// DateIn returns midnight of the entry's day in the account's time zone loc. A nil loc means UTC.
+func (e TimeEntry) DateIn(loc *time.Location) (time.Time, error) {
+	if e.Date.IsZero() {
+		return time.Time{}, fmt.Errorf("time entry %d: date: %w", e.ID, ErrNotRecorded)
+	}
+	return wallClock(e.Date, loc), nil
+}
+
+// StartIn returns the start of the entry, combining Date and StartTime in the account's time zone loc.
+// A nil loc means UTC.
+func (e TimeEntry) StartIn(loc *time.Location) (time.Time, error) {
+	date, err := e.DateIn(loc)
 	if err != nil {
-		return 0, err
+		return time.Time{}, err
+	}
+	if e.StartTime.IsZero() {
+		return time.Time{}, fmt.Errorf("time entry %d: start time: %w", e.ID, ErrNotRecorded)
 	}
-	return dur, nil
+	return atClock(date, e.StartTime), nil
 }
 
-func (e TimeEntry) DateParsed() time.Time {
-	date, err := time.Parse(DateFormat, e.Date)
+// EndIn returns the end of the entry, combining Date and EndTime in the account's time zone loc.
+// Entries ending before they start are assumed to span midnight. A nil loc means UTC.
+func (e TimeEntry) EndIn(loc *time.Location) (time.Time, error) {
+	start, err := e.StartIn(loc)
 	if err != nil {
-		log.Fatal("could not parse entry date:", e.Date)
+		return time.Time{}, err
+	}
+	if e.EndTime.IsZero() {
+		return time.Time{}, fmt.Errorf("time entry %d: end time: %w", e.ID, ErrNotRecorded)
+	}
+	end := atClock(wallClock(e.Date, loc), e.EndTime)
+	if end.Before(start) {
+		end = atClock(wallClock(e.Date.AddDate(0, 0, 1), loc), e.EndTime)
 	}
-	return date
+	return end, nil
+}
+
+// LastModifyIn returns the time of the entry's last change in the account's time zone loc. A nil loc means UTC.
+func (e TimeEntry) LastModifyIn(loc *time.Location) (time.Time, error) {
+	if e.LastModify.IsZero() {
+		return time.Time{}, fmt.Errorf("time entry %d: last modify: %w", e.ID, ErrNotRecorded)
+	}
+	return wallClock(e.LastModify, loc), nil
+}
+
+// wallClock returns the same wall clock time as t (given in UTC) in loc.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// atClock returns the given day at the time of day of clock.
+func atClock(day time.Time, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, day.Location())
 }
 
 func (e TimeEntry) HasDescription() bool {
@@ -63,50 +230,239 @@ func (e TimeEntry) IsBillable() bool {
 	return e.Billable > 0
 }
 
+// HasTag is true if the entry is tagged with name, ignoring case.
+func (e TimeEntry) HasTag(name string) bool {
+	for _, tag := range e.Tags {
+		if strings.EqualFold(strings.TrimSpace(tag.Name), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeFormat is the layout of the start and end times of a TimeEntry.
+const TimeFormat = "15:04:05"
+
+// TimeEntryRequest holds the data sent to create or update a time entry.
+// Either Duration or both StartTime and EndTime must be set; only the time of day of StartTime and EndTime is used.
+type TimeEntryRequest struct {
+	Date      time.Time
+	Duration  time.Duration
+	StartTime time.Time
+	EndTime   time.Time
+	TaskID    ID
+	Note      string
+	Billable  bool
+}
+
+// form converts the request to the form values expected by TimeCamp.
+func (r TimeEntryRequest) form() (url.Values, error) {
+	if r.Date.IsZero() {
+		return nil, fmt.Errorf("time entry: Date must be set")
+	}
+	hasClock := !r.StartTime.IsZero() && !r.EndTime.IsZero()
+	if r.Duration <= 0 && !hasClock {
+		return nil, fmt.Errorf("time entry: either Duration or StartTime and EndTime must be set")
+	}
+
+	form := url.Values{}
+	form.Set("date", r.Date.Format(DateFormat))
+	if r.Duration > 0 {
+		form.Set("duration", strconv.FormatInt(int64(r.Duration/time.Second), 10))
+	}
+	if hasClock {
+		form.Set("start_time", r.StartTime.Format(TimeFormat))
+		form.Set("end_time", r.EndTime.Format(TimeFormat))
+	}
+	if r.TaskID != 0 {
+		form.Set("task_id", r.TaskID.String())
+	}
+	form.Set("note", r.Note)
+	if r.Billable {
+		form.Set("billable", "1")
+	} else {
+		form.Set("billable", "0")
+	}
+	return form, nil
+}
+
+// CreateTimeEntry wraps the "POST /entries" api endpoint and returns the ID of the new entry.
+func CreateTimeEntry(c Connection, entry TimeEntryRequest) (ID, error) {
+	return CreateTimeEntryContext(context.Background(), c, entry)
+}
+
+// CreateTimeEntryContext is like CreateTimeEntry, but the request is bound to ctx.
+func CreateTimeEntryContext(ctx context.Context, c Connection, entry TimeEntryRequest) (ID, error) {
+	form, err := entry.form()
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := do(ctx, c, http.MethodPost, endpointUrl(c, "entries"), form)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		EntryID ID `json:"entry_id"`
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return 0, err
+	}
+	if result.EntryID <= 0 {
+		return 0, fmt.Errorf("CreateTimeEntry: no entry ID returned")
+	}
+	return result.EntryID, nil
+}
+
+// UpdateTimeEntry wraps the "PUT /entries" api endpoint.
+// All fields of entry are sent, so it should contain the complete new state of the time entry.
+func UpdateTimeEntry(c Connection, id ID, entry TimeEntryRequest) error {
+	return UpdateTimeEntryContext(context.Background(), c, id, entry)
+}
+
+// UpdateTimeEntryContext is like UpdateTimeEntry, but the request is bound to ctx.
+func UpdateTimeEntryContext(ctx context.Context, c Connection, id ID, entry TimeEntryRequest) error {
+	if id <= 0 {
+		return fmt.Errorf("UpdateTimeEntry: invalid entry ID %d", id)
+	}
+	form, err := entry.form()
+	if err != nil {
+		return err
+	}
+	form.Set("id", id.String())
+
+	_, err = do(ctx, c, http.MethodPut, endpointUrl(c, "entries"), form)
+	return err
+}
+
+// DeleteTimeEntry wraps the "DELETE /entries" api endpoint.
+func DeleteTimeEntry(c Connection, id ID) error {
+	return DeleteTimeEntryContext(context.Background(), c, id)
+}
+
+// DeleteTimeEntryContext is like DeleteTimeEntry, but the request is bound to ctx.
+func DeleteTimeEntryContext(ctx context.Context, c Connection, id ID) error {
+	if id <= 0 {
+		return fmt.Errorf("DeleteTimeEntry: invalid entry ID %d", id)
+	}
+	form := url.Values{}
+	form.Set("id", id.String())
+
+	_, err := do(ctx, c, http.MethodDelete, endpointUrl(c, "entries"), form)
+	return err
+}
+
 // TimeEntryParams query parameters.
+// From and To are inclusive and only their calendar day is used, see DateRange.
 type TimeEntryParams struct {
 	From  time.Time
 	To    time.Time
 	Tasks []Task
+	// Users restricts the entries to those of the given users. If nil / empty, all users' entries are returned.
+	Users []User
+	// WithSubtasks also returns the entries of all subtasks of Tasks.
+	WithSubtasks bool
+	// IncludeProject sets TimeCamp's include_project option.
+	IncludeProject bool
+	// RoundDuration rounds the durations according to the account's rounding settings.
+	RoundDuration bool
+	// WithTags fills the entries' Tags.
+	WithTags bool
+	// Window splits the range into several requests, e.g. one per month, for ranges too long for a single request.
+	// The merged entries are de-duplicated and sorted by date.
+	Window Window
+	// Concurrency is the maximum number of parallel requests if Window is set. Defaults to 4.
+	Concurrency int
 }
 
 // GetTimeEntries wraps the "GET /entries" api endpoint.
 // If params.Tasks is nil / empty, all tasks' entries are returned.
+// Use GetTimeEntriesInRange for common ranges, e.g. GetTimeEntriesInRange(c, api.ThisWeek(loc), api.TimeEntryParams{}).
 func GetTimeEntries(con Connection, params TimeEntryParams) ([]TimeEntry, error) {
-	queryUrl, err := timeEntryUrl(con, params)
-	if err != nil {
-		return nil, err
-	}
+	return GetTimeEntriesContext(context.Background(), con, params)
+}
 
-	data, err := httpGet(queryUrl)
-	if err != nil {
-		return nil, err
+// GetTimeEntriesContext is like GetTimeEntries, but the request is bound to ctx.
+func GetTimeEntriesContext(ctx context.Context, con Connection, params TimeEntryParams) ([]TimeEntry, error) {
+	if params.Window != WindowNone {
+		return getTimeEntriesChunked(ctx, con, params)
 	}
 
-	var result []TimeEntry
-	err = json.Unmarshal(data, &result)
-	if err != nil {
+	it := IterateTimeEntries(ctx, con, params)
+	defer it.Close()
+
+	// Like TimeCamp's response, no entries are an empty slice, not nil.
+	result := []TimeEntry{}
+	for it.Next() {
+		result = append(result, it.Entry())
+	}
+	if err := it.Err(); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// GetTimeEntriesInRange is like GetTimeEntries, but returns the entries of the days in r.
+// From and To of params are ignored, the other fields are used as in GetTimeEntries.
+func GetTimeEntriesInRange(con Connection, r DateRange, params TimeEntryParams) ([]TimeEntry, error) {
+	return GetTimeEntriesInRangeContext(context.Background(), con, r, params)
+}
+
+// GetTimeEntriesInRangeContext is like GetTimeEntriesInRange, but the request is bound to ctx.
+func GetTimeEntriesInRangeContext(ctx context.Context, con Connection, r DateRange, params TimeEntryParams) ([]TimeEntry, error) {
+	params.From, params.To = r.From, r.To
+	return GetTimeEntriesContext(ctx, con, params)
+}
+
 func timeEntryUrl(connection Connection, params TimeEntryParams) (string, error) {
-	if !params.From.Before(params.To) {
-		return "", fmt.Errorf("GetTimeEntries: From date must be before To date")
+	if err := params.Range().Validate(); err != nil {
+		return "", fmt.Errorf("GetTimeEntries: %w", err)
 	}
 
 	var taskIds []string
 	for _, task := range params.Tasks {
-		taskIds = append(taskIds, strconv.FormatInt(int64(task.TaskID), 10))
+		if task.TaskID <= 0 {
+			return "", fmt.Errorf("GetTimeEntries: invalid task ID %d", task.TaskID)
+		}
+		taskIds = append(taskIds, task.TaskID.String())
+	}
+	if params.WithSubtasks && len(taskIds) == 0 {
+		return "", fmt.Errorf("GetTimeEntries: WithSubtasks requires Tasks")
+	}
+
+	var userIds []string
+	for _, user := range params.Users {
+		if user.UserID <= 0 {
+			return "", fmt.Errorf("GetTimeEntries: invalid user ID %d", user.UserID)
+		}
+		userIds = append(userIds, user.UserID.String())
+	}
+
+	options := ""
+	if len(userIds) > 0 {
+		options += "/user_ids/" + strings.Join(userIds, ",")
+	}
+	if params.WithSubtasks {
+		options += "/with_subtasks/1"
+	}
+	if params.IncludeProject {
+		options += "/include_project/1"
+	}
+	if params.RoundDuration {
+		options += "/round_duration/1"
+	}
+	if params.WithTags {
+		options += "/opt_fields/tags"
 	}
 
-	queryUrl, err := url.Parse(connection.ApiUrl + "/entries/format/json/api_token/" + connection.Token + "/from/" +
+	queryUrl, err := url.Parse(endpointUrl(connection, "entries") + "/from/" +
 		params.From.Format(DateFormat) + "/to/" + params.To.Format(DateFormat) +
-		"/task_ids/" + strings.Join(taskIds, ","))
+		"/task_ids/" + strings.Join(taskIds, ",") + options)
 	if err != nil {
-		return "", err
+		return "", redactError(connection, err)
 	}
 
 	return queryUrl.String(), err
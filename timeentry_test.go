@@ -1,6 +1,13 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -31,6 +38,34 @@ func Test_timeEntryUrl(t *testing.T) {
 			},
 			want:    "URL/entries/format/json/api_token/TOKEN/from/0001-01-01/to/0001-01-01/task_ids/",
 			wantErr: false,
+		}, {
+			name: "Same Day",
+			args: args{
+				Connection{
+					ApiUrl: "URL",
+					Token:  "TOKEN",
+				},
+				TimeEntryParams{
+					From: time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC),
+					To:   time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want:    "URL/entries/format/json/api_token/TOKEN/from/2021-01-15/to/2021-01-15/task_ids/",
+			wantErr: false,
+		}, {
+			name: "Time Of Day Ignored",
+			args: args{
+				Connection{
+					ApiUrl: "URL",
+					Token:  "TOKEN",
+				},
+				TimeEntryParams{
+					From: time.Date(2021, 01, 15, 18, 0, 0, 0, time.UTC),
+					To:   time.Date(2021, 01, 15, 9, 0, 0, 0, time.UTC),
+				},
+			},
+			want:    "URL/entries/format/json/api_token/TOKEN/from/2021-01-15/to/2021-01-15/task_ids/",
+			wantErr: false,
 		}, {
 			name: "To Date Before From Date",
 			args: args{
@@ -64,6 +99,51 @@ func Test_timeEntryUrl(t *testing.T) {
 			},
 			want:    "URL/entries/format/json/api_token/TOKEN/from/0001-01-01/to/0001-01-01/task_ids/1,2",
 			wantErr: false,
+		}, {
+			name: "All Options",
+			args: args{
+				Connection{
+					ApiUrl: "URL",
+					Token:  "TOKEN",
+				},
+				TimeEntryParams{
+					From:           time.Time{},
+					To:             time.Time{}.Add(time.Hour),
+					Tasks:          []Task{{TaskID: 1}},
+					Users:          []User{{UserID: 7}, {UserID: 8}},
+					WithSubtasks:   true,
+					IncludeProject: true,
+					RoundDuration:  true,
+					WithTags:       true,
+				},
+			},
+			want: "URL/entries/format/json/api_token/TOKEN/from/0001-01-01/to/0001-01-01/task_ids/1" +
+				"/user_ids/7,8/with_subtasks/1/include_project/1/round_duration/1/opt_fields/tags",
+			wantErr: false,
+		}, {
+			name: "Invalid Task",
+			args: args{
+				Connection{ApiUrl: "URL", Token: "TOKEN"},
+				TimeEntryParams{From: time.Time{}, To: time.Time{}.Add(time.Hour), Tasks: []Task{{Name: "No ID"}}},
+			},
+			want:    "",
+			wantErr: true,
+		}, {
+			name: "Invalid User",
+			args: args{
+				Connection{ApiUrl: "URL", Token: "TOKEN"},
+				TimeEntryParams{From: time.Time{}, To: time.Time{}.Add(time.Hour), Users: []User{{DisplayName: "No ID"}}},
+			},
+			want:    "",
+			wantErr: true,
+		}, {
+			name: "Subtasks Without Tasks",
+			args: args{
+				Connection{ApiUrl: "URL", Token: "TOKEN"},
+				TimeEntryParams{From: time.Time{}, To: time.Time{}.Add(time.Hour), WithSubtasks: true},
+			},
+			want:    "",
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -79,3 +159,218 @@ func Test_timeEntryUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeEntryRequest_form(t *testing.T) {
+	date := time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		request TimeEntryRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Duration",
+			request: TimeEntryRequest{Date: date, Duration: 90 * time.Minute, TaskID: 12, Note: "meeting", Billable: true},
+			want:    "billable=1&date=2021-01-15&duration=5400&note=meeting&task_id=12",
+		}, {
+			name: "Start And End",
+			request: TimeEntryRequest{
+				Date:      date,
+				StartTime: time.Date(2021, 01, 15, 9, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2021, 01, 15, 10, 30, 0, 0, time.UTC),
+			},
+			want: "billable=0&date=2021-01-15&end_time=10%3A30%3A00&note=&start_time=09%3A00%3A00",
+		}, {
+			name:    "Missing Date",
+			request: TimeEntryRequest{Duration: time.Hour},
+			wantErr: true,
+		}, {
+			name:    "Missing Duration",
+			request: TimeEntryRequest{Date: date, StartTime: time.Date(2021, 01, 15, 9, 0, 0, 0, time.UTC)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.request.form()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("form() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Encode() != tt.want {
+				t.Errorf("form() got = %v, want %v", got.Encode(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeEntryWrites(t *testing.T) {
+	type received struct {
+		method string
+		form   url.Values
+	}
+	var got []received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		got = append(got, received{r.Method, form})
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"entry_id":"4711"}`))
+		}
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+	request := TimeEntryRequest{Date: time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC), Duration: time.Hour, TaskID: 1}
+
+	id, err := CreateTimeEntry(c, request)
+	if err != nil || id != 4711 {
+		t.Fatalf("CreateTimeEntry() = %d, %v, want 4711", id, err)
+	}
+	if err := UpdateTimeEntry(c, id, request); err != nil {
+		t.Fatalf("UpdateTimeEntry() error = %v", err)
+	}
+	if err := DeleteTimeEntry(c, id); err != nil {
+		t.Fatalf("DeleteTimeEntry() error = %v", err)
+	}
+	if err := DeleteTimeEntry(c, 0); err == nil {
+		t.Errorf("DeleteTimeEntry() with invalid ID expected error")
+	}
+
+	want := []received{
+		{http.MethodPost, url.Values{"date": {"2021-01-15"}, "duration": {"3600"}, "task_id": {"1"}, "note": {""}, "billable": {"0"}}},
+		{http.MethodPut, url.Values{"id": {"4711"}, "date": {"2021-01-15"}, "duration": {"3600"}, "task_id": {"1"}, "note": {""}, "billable": {"0"}}},
+		{http.MethodDelete, url.Values{"id": {"4711"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("server received %v, want %v", got, want)
+	}
+}
+
+func TestTimeEntry_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		want      TimeEntry
+		wantField string
+	}{
+		{
+			name: "TimeCamp Strings",
+			json: `{"id":101,"duration":"5400","user_id":"7","user_name":"Ann","task_id":"12",
+				"last_modify":"2021-01-15 18:00:01","date":"2021-01-15","start_time":"09:00:00","end_time":"10:30:00",
+				"locked":"1","name":"Task","addons_external_id":"","billable":1,"invoiceId":"0","color":"","description":"Review"}`,
+			want: TimeEntry{
+				ID:          101,
+				Duration:    90 * time.Minute,
+				UserID:      7,
+				UserName:    "Ann",
+				TaskID:      12,
+				LastModify:  time.Date(2021, 01, 15, 18, 0, 1, 0, time.UTC),
+				Date:        time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC),
+				StartTime:   time.Date(0, 01, 01, 9, 0, 0, 0, time.UTC),
+				EndTime:     time.Date(0, 01, 01, 10, 30, 0, 0, time.UTC),
+				Locked:      true,
+				Name:        "Task",
+				Billable:    1,
+				InvoiceID:   "0",
+				Description: "Review",
+			},
+		}, {
+			name: "Numbers And Empty Values",
+			json: `{"id":"102","duration":60,"user_id":7,"task_id":12,"date":"2021-01-15","start_time":"",
+				"last_modify":"0000-00-00 00:00:00","locked":false,"billable":"0","invoiceId":5}`,
+			want: TimeEntry{
+				ID:        102,
+				Duration:  time.Minute,
+				UserID:    7,
+				TaskID:    12,
+				Date:      time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC),
+				InvoiceID: "5",
+			},
+		},
+		{name: "Invalid Duration", json: `{"id":103,"duration":"1h"}`, wantField: "duration"},
+		{name: "Invalid Date", json: `{"id":104,"date":"15.01.2021"}`, wantField: "date"},
+		{name: "Invalid Locked", json: `{"id":105,"locked":"yes"}`, wantField: "locked"},
+		{name: "Invalid Task ID", json: `{"id":106,"task_id":{}}`, wantField: "task_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TimeEntry
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantField != "" {
+				var decodeErr *DecodeError
+				if !errors.As(err, &decodeErr) {
+					t.Fatalf("UnmarshalJSON() error = %v, want *DecodeError", err)
+				}
+				if decodeErr.Field != tt.wantField || decodeErr.ID == "" {
+					t.Errorf("UnmarshalJSON() error = %v, want field %s with entry ID", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() got = %+v, want %+v", got, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			var roundTrip TimeEntry
+			if err := json.Unmarshal(data, &roundTrip); err != nil || !reflect.DeepEqual(roundTrip, got) {
+				t.Errorf("round trip got = %+v, %v, want %+v", roundTrip, err, got)
+			}
+		})
+	}
+}
+
+func TestTimeEntry_TimeAccessors(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available:", err)
+	}
+	entry := TimeEntry{
+		ID:         1,
+		Date:       time.Date(2021, 01, 15, 0, 0, 0, 0, time.UTC),
+		StartTime:  time.Date(0, 01, 01, 22, 0, 0, 0, time.UTC),
+		EndTime:    time.Date(0, 01, 01, 1, 30, 0, 0, time.UTC),
+		LastModify: time.Date(2021, 01, 16, 8, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name     string
+		accessor func(*time.Location) (time.Time, error)
+		loc      *time.Location
+		want     time.Time
+	}{
+		{name: "Date", accessor: entry.DateIn, loc: berlin, want: time.Date(2021, 01, 15, 0, 0, 0, 0, berlin)},
+		{name: "Start", accessor: entry.StartIn, loc: berlin, want: time.Date(2021, 01, 15, 22, 0, 0, 0, berlin)},
+		{name: "End After Midnight", accessor: entry.EndIn, loc: berlin, want: time.Date(2021, 01, 16, 1, 30, 0, 0, berlin)},
+		{name: "Last Modify", accessor: entry.LastModifyIn, loc: berlin, want: time.Date(2021, 01, 16, 8, 0, 0, 0, berlin)},
+		{name: "Nil Location", accessor: entry.StartIn, loc: nil, want: time.Date(2021, 01, 15, 22, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.accessor(tt.loc)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !got.Equal(tt.want) || got.Location() != tt.want.Location() {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	missing := TimeEntry{ID: 2, Date: entry.Date}
+	for name, accessor := range map[string]func(*time.Location) (time.Time, error){
+		"Start":       missing.StartIn,
+		"End":         missing.EndIn,
+		"Last Modify": missing.LastModifyIn,
+		"Date":        TimeEntry{ID: 3}.DateIn,
+	} {
+		if _, err := accessor(berlin); !errors.Is(err, ErrNotRecorded) {
+			t.Errorf("%s: error = %v, want ErrNotRecorded", name, err)
+		}
+	}
+}
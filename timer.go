@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DateTimeFormat is the layout TimeCamp uses for timestamps.
+const DateTimeFormat = "2006-01-02 15:04:05"
+
+// Timer is a live TimeCamp timer.
+//
+// API Docs: https://github.com/timecamp/timecamp-api/blob/master/sections/timer.md
+type Timer struct {
+	TimerID ID
+	EntryID ID
+	TaskID  ID
+	// StartedAt is in the connection's Location. It is zero if TimeCamp did not report it.
+	StartedAt time.Time
+	Elapsed   time.Duration
+}
+
+// timerResponse maps the JSON returned by the different /timer actions.
+type timerResponse struct {
+	IsTimerRunning bool    `json:"isTimerRunning"`
+	TimerID        ID      `json:"timer_id"`
+	NewTimerID     ID      `json:"new_timer_id"`
+	EntryID        ID      `json:"entry_id"`
+	TaskID         ID      `json:"task_id"`
+	StartTime      string  `json:"start_time"`
+	StartedAt      string  `json:"started_at"`
+	Elapsed        flexInt `json:"elapsed"`
+}
+
+func (r timerResponse) timer(loc *time.Location) (Timer, error) {
+	timer := Timer{
+		TimerID: r.TimerID,
+		EntryID: r.EntryID,
+		TaskID:  r.TaskID,
+		Elapsed: time.Duration(r.Elapsed) * time.Second,
+	}
+	if r.NewTimerID != 0 {
+		timer.TimerID = r.NewTimerID
+	}
+
+	start := r.StartTime
+	if start == "" {
+		start = r.StartedAt
+	}
+	if start != "" {
+		if loc == nil {
+			loc = time.UTC
+		}
+		startedAt, err := time.ParseInLocation(DateTimeFormat, start, loc)
+		if err != nil {
+			return Timer{}, fmt.Errorf("timer: invalid start time %q", start)
+		}
+		timer.StartedAt = startedAt
+	}
+	return timer, nil
+}
+
+// timerAction posts an action to the /timer endpoint.
+func timerAction(ctx context.Context, c Connection, form url.Values) (timerResponse, error) {
+	var result timerResponse
+	data, err := do(ctx, c, http.MethodPost, endpointUrl(c, "timer"), form)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// StartTimer starts the timer for the given task, stopping a running timer.
+// A taskID of 0 starts a timer without task.
+func StartTimer(c Connection, taskID ID, note string) (Timer, error) {
+	return StartTimerContext(context.Background(), c, taskID, note)
+}
+
+// StartTimerContext is like StartTimer, but the request is bound to ctx.
+func StartTimerContext(ctx context.Context, c Connection, taskID ID, note string) (Timer, error) {
+	form := url.Values{}
+	form.Set("action", "start")
+	if taskID != 0 {
+		form.Set("task_id", taskID.String())
+	}
+	if note != "" {
+		form.Set("note", note)
+	}
+
+	result, err := timerAction(ctx, c, form)
+	if err != nil {
+		return Timer{}, err
+	}
+	timer, err := result.timer(c.Location)
+	if err != nil {
+		return Timer{}, err
+	}
+	if timer.TaskID == 0 {
+		timer.TaskID = taskID
+	}
+	return timer, nil
+}
+
+// StopTimer stops the running timer and returns it with the final elapsed time.
+func StopTimer(c Connection) (Timer, error) {
+	return StopTimerContext(context.Background(), c)
+}
+
+// StopTimerContext is like StopTimer, but the request is bound to ctx.
+func StopTimerContext(ctx context.Context, c Connection) (Timer, error) {
+	form := url.Values{}
+	form.Set("action", "stop")
+
+	result, err := timerAction(ctx, c, form)
+	if err != nil {
+		return Timer{}, err
+	}
+	return result.timer(c.Location)
+}
+
+// GetRunningTimer returns the running timer, or nil if no timer is running.
+func GetRunningTimer(c Connection) (*Timer, error) {
+	return GetRunningTimerContext(context.Background(), c)
+}
+
+// GetRunningTimerContext is like GetRunningTimer, but the request is bound to ctx.
+func GetRunningTimerContext(ctx context.Context, c Connection) (*Timer, error) {
+	form := url.Values{}
+	form.Set("action", "status")
+
+	result, err := timerAction(ctx, c, form)
+	if err != nil {
+		return nil, err
+	}
+	if !result.IsTimerRunning {
+		return nil, nil
+	}
+	timer, err := result.timer(c.Location)
+	if err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	running := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the client call instead of the test, the handler runs on the server's goroutine.
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch r.PostForm.Get("action") {
+		case "start":
+			running = true
+			_, _ = w.Write([]byte(`{"new_timer_id":"7","entry_id":8}`))
+		case "stop":
+			running = false
+			_, _ = w.Write([]byte(`{"elapsed":"90","entry_id":"8","timer_id":"7"}`))
+		case "status":
+			if running {
+				_, _ = w.Write([]byte(`{"isTimerRunning":true,"elapsed":"30","timer_id":"7","entry_id":"8","task_id":"12","start_time":"2021-01-15 09:00:00"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"isTimerRunning":false,"elapsed":0}`))
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	if timer, err := GetRunningTimer(c); err != nil || timer != nil {
+		t.Fatalf("GetRunningTimer() = %v, %v, want nil timer", timer, err)
+	}
+
+	started, err := StartTimer(c, 12, "coding")
+	if err != nil {
+		t.Fatalf("StartTimer() error = %v", err)
+	}
+	if want := (Timer{TimerID: 7, EntryID: 8, TaskID: 12}); started != want {
+		t.Errorf("StartTimer() got = %+v, want %+v", started, want)
+	}
+
+	timer, err := GetRunningTimer(c)
+	if err != nil {
+		t.Fatalf("GetRunningTimer() error = %v", err)
+	}
+	want := &Timer{
+		TimerID:   7,
+		EntryID:   8,
+		TaskID:    12,
+		StartedAt: time.Date(2021, 01, 15, 9, 0, 0, 0, time.UTC),
+		Elapsed:   30 * time.Second,
+	}
+	if !reflect.DeepEqual(timer, want) {
+		t.Errorf("GetRunningTimer() got = %+v, want %+v", timer, want)
+	}
+
+	stopped, err := StopTimer(c)
+	if err != nil {
+		t.Fatalf("StopTimer() error = %v", err)
+	}
+	if stopped.Elapsed != 90*time.Second || stopped.EntryID != 8 {
+		t.Errorf("StopTimer() got = %+v", stopped)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Role is a user's role in a TimeCamp group or task.
+type Role int
+
+const (
+	RoleAdministrator Role = 1
+	RoleSupervisor    Role = 2
+	RoleUser          Role = 3
+	RoleGuest         Role = 5
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleAdministrator:
+		return "administrator"
+	case RoleSupervisor:
+		return "supervisor"
+	case RoleUser:
+		return "user"
+	case RoleGuest:
+		return "guest"
+	default:
+		return "role " + strconv.Itoa(int(r))
+	}
+}
+
+// User maps the JSON returned by TimeCamp API /users.
+//
+// API Docs: https://github.com/timecamp/timecamp-api/blob/master/sections/users.md
+type User struct {
+	UserID      ID
+	GroupID     ID
+	Email       string
+	DisplayName string
+	// Role is 0 if TimeCamp did not report it.
+	Role       Role
+	LoginCount int
+	LoginTime  string
+}
+
+type rawUser struct {
+	UserID      ID      `json:"user_id"`
+	GroupID     ID      `json:"group_id"`
+	Email       string  `json:"email"`
+	DisplayName string  `json:"display_name"`
+	RoleID      flexInt `json:"role_id"`
+	LoginCount  flexInt `json:"login_count"`
+	LoginTime   string  `json:"login_time"`
+}
+
+func (u *User) UnmarshalJSON(data []byte) error {
+	var raw rawUser
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*u = User{
+		UserID:      raw.UserID,
+		GroupID:     raw.GroupID,
+		Email:       raw.Email,
+		DisplayName: raw.DisplayName,
+		Role:        Role(raw.RoleID),
+		LoginCount:  int(raw.LoginCount),
+		LoginTime:   raw.LoginTime,
+	}
+	return nil
+}
+
+// Group maps the JSON returned by TimeCamp API /group.
+//
+// API Docs: https://github.com/timecamp/timecamp-api/blob/master/sections/groups.md
+type Group struct {
+	GroupID  ID     `json:"group_id"`
+	Name     string `json:"name"`
+	ParentID ID     `json:"parent_id"`
+}
+
+// GetUsers wraps the "GET /users" api endpoint.
+func GetUsers(c Connection) ([]User, error) {
+	return GetUsersContext(context.Background(), c)
+}
+
+// GetUsersContext is like GetUsers, but the request is bound to ctx.
+func GetUsersContext(ctx context.Context, c Connection) ([]User, error) {
+	data, err := httpGet(ctx, c, endpointUrl(c, "users"))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []User
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetUser wraps the "GET /user" api endpoint, returning the user with the given ID.
+func GetUser(c Connection, id ID) (User, error) {
+	return GetUserContext(context.Background(), c, id)
+}
+
+// GetUserContext is like GetUser, but the request is bound to ctx.
+func GetUserContext(ctx context.Context, c Connection, id ID) (User, error) {
+	if id <= 0 {
+		return User{}, fmt.Errorf("GetUser: invalid user ID %d", id)
+	}
+
+	data, err := httpGet(ctx, c, endpointUrl(c, "user")+"/user_id/"+id.String())
+	if err != nil {
+		return User{}, err
+	}
+
+	var result User
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return User{}, err
+	}
+	if result.UserID != id {
+		return User{}, fmt.Errorf("GetUser: user with ID %d not found", id)
+	}
+	return result, nil
+}
+
+// GetGroups wraps the "GET /group" api endpoint.
+func GetGroups(c Connection) ([]Group, error) {
+	return GetGroupsContext(context.Background(), c)
+}
+
+// GetGroupsContext is like GetGroups, but the request is bound to ctx.
+func GetGroupsContext(ctx context.Context, c Connection) ([]Group, error) {
+	data, err := httpGet(ctx, c, endpointUrl(c, "group"))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Group
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUsersAndGroups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/users/"):
+			_, _ = w.Write([]byte(`[{"group_id":"3","user_id":"7","email":"ann@example.com","display_name":"Ann","login_count":"12"},
+				{"group_id":3,"user_id":8,"email":"bob@example.com","display_name":"Bob","role_id":"5"}]`))
+		case strings.HasPrefix(r.URL.Path, "/user/") && strings.HasSuffix(r.URL.Path, "/user_id/7"):
+			_, _ = w.Write([]byte(`{"user_id":"7","email":"ann@example.com","display_name":"Ann"}`))
+		case strings.HasPrefix(r.URL.Path, "/user/"):
+			_, _ = w.Write([]byte(`{}`))
+		case strings.HasPrefix(r.URL.Path, "/group/"):
+			_, _ = w.Write([]byte(`[{"group_id":"3","name":"Team","parent_id":"0"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c := Connection{ApiUrl: server.URL, Token: "TOKEN"}
+
+	users, err := GetUsers(c)
+	if err != nil {
+		t.Fatalf("GetUsers() error = %v", err)
+	}
+	wantUsers := []User{
+		{UserID: 7, GroupID: 3, Email: "ann@example.com", DisplayName: "Ann", LoginCount: 12},
+		{UserID: 8, GroupID: 3, Email: "bob@example.com", DisplayName: "Bob", Role: RoleGuest},
+	}
+	if !reflect.DeepEqual(users, wantUsers) {
+		t.Errorf("GetUsers() got = %+v, want %+v", users, wantUsers)
+	}
+
+	user, err := GetUser(c, 7)
+	if err != nil || user.DisplayName != "Ann" {
+		t.Errorf("GetUser() got = %+v, %v", user, err)
+	}
+	if _, err := GetUser(c, 9); err == nil {
+		t.Errorf("GetUser() for unknown user expected error")
+	}
+
+	groups, err := GetGroups(c)
+	if err != nil {
+		t.Fatalf("GetGroups() error = %v", err)
+	}
+	if want := []Group{{GroupID: 3, Name: "Team"}}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("GetGroups() got = %+v, want %+v", groups, want)
+	}
+}